@@ -0,0 +1,220 @@
+package logy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BackpressurePolicy decides what an asynchronous logger does with a new
+// entry when its queue is full.
+type BackpressurePolicy int
+
+const (
+	// Block makes the logging call wait for room in the queue.
+	Block BackpressurePolicy = iota
+	// DropNewest discards the new entry.
+	DropNewest
+	// DropOldest discards the oldest queued entry to make room, unless it is
+	// a Fatal or Panic entry: then it waits like Block.
+	DropOldest
+)
+
+// defaultQueueSize is used when AsyncOptions.QueueSize is not set.
+const defaultQueueSize = 1024
+
+// AsyncOptions configures the asynchronous mode of a Logger.
+type AsyncOptions struct {
+	// QueueSize is the number of entries waiting to be written before the
+	// Policy kicks in. Defaults to 1024.
+	QueueSize int
+
+	// Policy applies when the queue is full. Fatal and Panic entries always
+	// wait for room and are never dropped by DropOldest.
+	Policy BackpressurePolicy
+}
+
+// asyncQueue hands entries over to a goroutine writing them.
+type asyncQueue struct {
+	queue  chan *Entry
+	policy BackpressurePolicy
+
+	// sendMu keeps the queue from being closed during a send.
+	sendMu sync.RWMutex
+	closed bool
+
+	// pending counts the entries queued but not written yet.
+	mu      sync.Mutex
+	drained *sync.Cond
+	pending int
+
+	// dropped points to the counter of the logger, which outlives queues.
+	dropped *uint64
+	stopped chan struct{}
+}
+
+func newAsyncQueue(opts AsyncOptions, dropped *uint64) *asyncQueue {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	q := &asyncQueue{
+		queue:   make(chan *Entry, size),
+		policy:  opts.Policy,
+		dropped: dropped,
+		stopped: make(chan struct{}),
+	}
+	q.drained = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.stopped)
+	for entry := range q.queue {
+		entry.writeBuffered(entry.getBufferPool())
+		q.done(1)
+	}
+}
+
+// enqueue queues entry, which must not be used by the caller afterwards.
+// It returns false if the queue is closed and the entry was not taken.
+func (q *asyncQueue) enqueue(entry *Entry) bool {
+	q.sendMu.RLock()
+	defer q.sendMu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	q.add(1)
+	select {
+	case q.queue <- entry:
+		return true
+	default:
+	}
+
+	policy := q.policy
+	if entry.Level <= FatalLevel {
+		// never drop the entries the process is about to exit or panic on
+		policy = Block
+	}
+	switch policy {
+	case DropNewest:
+		q.drop()
+	case DropOldest:
+		for {
+			select {
+			case q.queue <- entry:
+				return true
+			default:
+			}
+			select {
+			case oldest := <-q.queue:
+				if oldest.Level <= FatalLevel {
+					// another goroutine is about to exit or panic on it:
+					// give it its place back and wait for room instead
+					q.queue <- oldest
+					q.queue <- entry
+					return true
+				}
+				q.drop()
+			default:
+			}
+		}
+	default:
+		q.queue <- entry
+	}
+	return true
+}
+
+func (q *asyncQueue) add(n int) {
+	q.mu.Lock()
+	q.pending += n
+	q.mu.Unlock()
+}
+
+func (q *asyncQueue) done(n int) {
+	q.mu.Lock()
+	q.pending -= n
+	if q.pending == 0 {
+		q.drained.Broadcast()
+	}
+	q.mu.Unlock()
+}
+
+func (q *asyncQueue) drop() {
+	atomic.AddUint64(q.dropped, 1)
+	q.done(1)
+}
+
+// flush waits until every queued entry has been written.
+func (q *asyncQueue) flush() {
+	q.mu.Lock()
+	for q.pending > 0 {
+		q.drained.Wait()
+	}
+	q.mu.Unlock()
+}
+
+// close stops accepting entries and waits for the queue to drain.
+func (q *asyncQueue) close() {
+	q.sendMu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.sendMu.Unlock()
+	<-q.stopped
+}
+
+// SetAsync switches the logger to asynchronous mode: entries are queued and
+// written by a background goroutine, so logging calls don't wait for slow
+// outputs. Calling it again replaces the queue after draining the old one.
+func (logger *Logger) SetAsync(opts AsyncOptions) {
+	logger.own()
+	logger.mu.Lock()
+	old := logger.async
+	logger.async = newAsyncQueue(opts, &logger.droppedEntries)
+	logger.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// DisableAsync drains the queue and switches the logger back to writing
+// entries synchronously.
+func (logger *Logger) DisableAsync() {
+	logger.mu.Lock()
+	old := logger.async
+	logger.async = nil
+	logger.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// Flush waits until every entry queued in asynchronous mode has been
+// written. It returns right away when the logger is synchronous.
+func (logger *Logger) Flush() {
+	if out := logger.output(); out != logger {
+		out.Flush()
+		return
+	}
+	logger.mu.Lock()
+	q := logger.async
+	logger.mu.Unlock()
+
+	if q != nil {
+		q.flush()
+	}
+}
+
+// DroppedEntries returns the number of entries discarded by the
+// backpressure policy since SetAsync was first called.
+func (logger *Logger) DroppedEntries() uint64 {
+	if out := logger.output(); out != logger {
+		return out.DroppedEntries()
+	}
+	return atomic.LoadUint64(&logger.droppedEntries)
+}
@@ -0,0 +1,137 @@
+package logy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowWriter takes a while for every write, so that the async queue fills up.
+type slowWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(20 * time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		policy  BackpressurePolicy
+		dropped bool
+		kept    string
+	}{
+		{"Block", Block, false, "info 0"},
+		{"DropNewest", DropNewest, true, "info 0"},
+		{"DropOldest", DropOldest, true, "info 5"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &slowWriter{}
+			l := New()
+			l.Out = out
+			l.Formatter = &TextFormatter{DisableTimestamp: true, DisableColors: true}
+			exitCode := -1
+			l.ExitFunc = func(code int) { exitCode = code }
+			l.SetAsync(AsyncOptions{QueueSize: 1, Policy: tt.policy})
+			defer l.Close()
+
+			for i := 0; i < 6; i++ {
+				l.Infof("info %d", i)
+			}
+			l.Fatal("fatal")
+
+			got := out.String()
+			if exitCode != 1 {
+				t.Errorf("exit code = %d, want 1", exitCode)
+			}
+			if !strings.Contains(got, "msg=fatal") {
+				t.Errorf("fatal entry missing from %q", got)
+			}
+			if !strings.Contains(got, tt.kept) {
+				t.Errorf("%q missing from %q", tt.kept, got)
+			}
+			if dropped := l.DroppedEntries(); (dropped > 0) != tt.dropped {
+				t.Errorf("dropped = %d", dropped)
+			}
+			if lines := strings.Count(got, "\n"); uint64(lines)+l.DroppedEntries() != 7 {
+				t.Errorf("%d lines written and %d dropped, want 7 in total", lines, l.DroppedEntries())
+			}
+		})
+	}
+}
+
+func TestAsyncNeverDropsPanic(t *testing.T) {
+	out := &slowWriter{}
+	l := New()
+	l.Out = out
+	l.SetAsync(AsyncOptions{QueueSize: 1, Policy: DropNewest})
+	defer l.Close()
+
+	for i := 0; i < 4; i++ {
+		l.Info(fmt.Sprint("info ", i))
+	}
+	func() {
+		defer func() { recover() }()
+		l.Panic("boom")
+	}()
+	if got := out.String(); !strings.Contains(got, "msg=boom") {
+		t.Errorf("panic entry missing from %q", got)
+	}
+}
+
+func TestAsyncDropOldestKeepsQueuedFatal(t *testing.T) {
+	// a queue without its writing goroutine, so that it stays full
+	q := &asyncQueue{queue: make(chan *Entry, 2), policy: DropOldest, dropped: new(uint64)}
+	q.drained = sync.NewCond(&q.mu)
+
+	// queued by a goroutine now waiting for it to be written before exiting
+	q.enqueue(&Entry{Level: FatalLevel, Message: "fatal"})
+
+	flooded := make(chan struct{})
+	go func() {
+		defer close(flooded)
+		for i := 0; i < 10; i++ {
+			q.enqueue(&Entry{Level: InfoLevel, Message: fmt.Sprint("flood ", i)})
+		}
+	}()
+	select {
+	case <-flooded:
+	case <-time.After(50 * time.Millisecond):
+		// waiting for room, as it should
+	}
+
+	var got []string
+	for {
+		select {
+		case entry := <-q.queue:
+			got = append(got, entry.Message)
+			q.done(1)
+			continue
+		case <-flooded:
+		}
+		if len(q.queue) == 0 {
+			break
+		}
+	}
+	if all := strings.Join(got, ","); !strings.Contains(all, "fatal") || !strings.HasSuffix(all, "flood 9") {
+		t.Errorf("written %s", all)
+	}
+	if dropped := atomic.LoadUint64(q.dropped); uint64(len(got))+dropped != 11 {
+		t.Errorf("%d entries written and %d dropped, want 11 in total", len(got), dropped)
+	}
+}
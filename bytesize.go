@@ -0,0 +1,160 @@
+package logy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes. Fields of this type are printed in human
+// units by the ConsoleFormatter, e.g. "1.5MiB". It is read from text such
+// as "100MB" by UnmarshalText, see ParseByteSize.
+type ByteSize int64
+
+// Byte size units, in powers of 1024.
+const (
+	KiB ByteSize = 1024
+	MiB          = 1024 * KiB
+	GiB          = 1024 * MiB
+	TiB          = 1024 * GiB
+)
+
+func (s ByteSize) String() string {
+	n := s
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	switch {
+	case n >= TiB:
+		return sign + formatUnit(float64(n)/float64(TiB), "TiB")
+	case n >= GiB:
+		return sign + formatUnit(float64(n)/float64(GiB), "GiB")
+	case n >= MiB:
+		return sign + formatUnit(float64(n)/float64(MiB), "MiB")
+	case n >= KiB:
+		return sign + formatUnit(float64(n)/float64(KiB), "KiB")
+	}
+	return fmt.Sprintf("%s%dB", sign, int64(n))
+}
+
+// formatUnit prints v with at most one decimal, dropping a trailing ".0".
+func formatUnit(v float64, unit string) string {
+	s := fmt.Sprintf("%.1f", v)
+	if len(s) > 2 && s[len(s)-2:] == ".0" {
+		s = s[:len(s)-2]
+	}
+	return s + unit
+}
+
+// byteUnits maps the lower-case unit suffixes ParseByteSize accepts to their
+// size.
+var byteUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"k":   KiB,
+	"kb":  1000,
+	"kib": KiB,
+	"m":   MiB,
+	"mb":  1000 * 1000,
+	"mib": MiB,
+	"g":   GiB,
+	"gb":  1000 * 1000 * 1000,
+	"gib": GiB,
+	"t":   TiB,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": TiB,
+}
+
+// ParseByteSize parses a size such as "512", "1.5GiB" or "100MB". Units are
+// case-insensitive: KB, MB, GB and TB are powers of 1000, KiB, MiB, GiB and
+// TiB as well as the single letters K, M, G and T are powers of 1024.
+func ParseByteSize(s string) (ByteSize, error) {
+	text := strings.TrimSpace(s)
+	if rest := strings.TrimPrefix(text, "-"); rest != text {
+		size, err := ParseByteSize(rest)
+		return -size, err
+	}
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(text)
+	}
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(text[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n, err := strconv.ParseInt(text[:i], 10, 64); err == nil {
+		if n > math.MaxInt64/int64(unit) {
+			return 0, fmt.Errorf("byte size %q out of range", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+	v, err := strconv.ParseFloat(text[:i], 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("byte size %q out of range", s)
+		}
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which is out of range itself
+	if v*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q out of range", s)
+	}
+	return ByteSize(v * float64(unit)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// UnmarshalJSON accepts a number of bytes as well as a string for
+// UnmarshalText.
+func (s *ByteSize) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		text, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return s.UnmarshalText([]byte(text))
+	}
+	return s.UnmarshalText(data)
+}
+
+// exactUnits are the units MarshalText tries, largest first.
+var exactUnits = []struct {
+	size ByteSize
+	name string
+}{
+	{TiB, "TiB"},
+	{1000 * 1000 * 1000 * 1000, "TB"},
+	{GiB, "GiB"},
+	{1000 * 1000 * 1000, "GB"},
+	{MiB, "MiB"},
+	{1000 * 1000, "MB"},
+	{KiB, "KiB"},
+	{1000, "KB"},
+}
+
+// MarshalText implements encoding.TextMarshaler. Unlike String it is exact,
+// using the largest unit dividing the size, e.g. "100MB" or "1536KiB", so
+// that UnmarshalText reads back the same size.
+func (s ByteSize) MarshalText() ([]byte, error) {
+	if s != 0 {
+		for _, unit := range exactUnits {
+			if s%unit.size == 0 {
+				return []byte(strconv.FormatInt(int64(s/unit.size), 10) + unit.name), nil
+			}
+		}
+	}
+	return []byte(strconv.FormatInt(int64(s), 10)), nil
+}
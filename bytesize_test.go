@@ -0,0 +1,55 @@
+package logy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want ByteSize
+		err  bool
+	}{
+		{"512", 512, false},
+		{"100MB", 100 * 1000 * 1000, false},
+		{"100mb", 100 * 1000 * 1000, false},
+		{"1.5GiB", 3 * GiB / 2, false},
+		{"10k", 10 * KiB, false},
+		{"2 MiB", 2 * MiB, false},
+		{"-1KiB", -KiB, false},
+		{"8EiB", 0, true},
+		{"MB", 0, true},
+		{"x", 0, true},
+		{"20000000TB", 0, true},
+		{"9223372036854775807", 9223372036854775807, false},
+		{"8388608TiB", 0, true},
+		{"8388607.99TiB", 0, false},
+		{"1e400KB", 0, true},
+	} {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseByteSize(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !tt.err && tt.want != 0 && got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestByteSizeJSONRoundTrip(t *testing.T) {
+	for _, size := range []ByteSize{0, 1, 100000000, 1536 * KiB, 3 * TiB, 1000*GiB + 1, -5 * KiB} {
+		data, err := json.Marshal(FileConfig{MaxSize: size})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c FileConfig
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if c.MaxSize != size {
+			t.Errorf("%d marshalled to %s, read back as %d", size, data, c.MaxSize)
+		}
+	}
+}
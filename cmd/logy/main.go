@@ -0,0 +1,356 @@
+// Command logy tails, filters and pretty-prints logs written by logy.
+//
+// Usage:
+//
+//	logy [flags] [file ...]
+//
+// Without files it reads standard input. Lines in the text or JSON format
+// are both understood; lines that can't be parsed are printed as they are.
+//
+// Examples:
+//
+//	logy -level warn -since 1h app.log
+//	logy -f -field request_id=42 app.log
+//	kubectl logs pod | logy -o json
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tortoise-daddy/logy"
+	"github.com/tortoise-daddy/logy/reader"
+)
+
+// fieldFilters collects the repeated -field flags.
+type fieldFilters map[string]string
+
+func (f fieldFilters) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFilters) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	f[k] = v
+	return nil
+}
+
+// filter decides which entries are printed.
+type filter struct {
+	level  logy.Level
+	since  time.Time
+	until  time.Time
+	fields fieldFilters
+}
+
+func (f *filter) match(entry *logy.Entry) bool {
+	if entry.Level > f.level {
+		return false
+	}
+	if !f.since.IsZero() && entry.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && entry.Time.After(f.until) {
+		return false
+	}
+	for k, want := range f.fields {
+		v, ok := entry.Data[k]
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// printer renders entries to stdout, one at a time.
+type printer struct {
+	mu        sync.Mutex
+	out       io.Writer
+	logger    *logy.Logger
+	formatter logy.Formatter
+	filter    *filter
+	opts      reader.Options
+}
+
+func (p *printer) line(line []byte) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	entry, err := reader.Parse(line, p.opts)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		// not ours, pass it through untouched
+		fmt.Fprintf(p.out, "%s\n", line)
+		return
+	}
+	if !p.filter.match(entry) {
+		return
+	}
+	entry.Logger = p.logger
+	serialized, err := p.formatter.Format(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logy: %v\n", err)
+		return
+	}
+	p.out.Write(serialized)
+}
+
+func main() {
+	var (
+		level    = flag.String("level", "trace", "least severe `level` to show")
+		since    = flag.String("since", "", "only show entries after `time`, RFC 3339 or a duration ago such as 15m")
+		until    = flag.String("until", "", "only show entries before `time`, RFC 3339 or a duration ago")
+		output   = flag.String("o", "console", "output `format`: console, json or text")
+		input    = flag.String("format", "auto", "input `format`: auto, json or text")
+		dataKey  = flag.String("data-key", "", "`key` the fields are nested under in JSON input")
+		follow   = flag.Bool("f", false, "follow the files as they grow and get rotated")
+		lines    = flag.Int("n", 10, "with -f, start from the last `N` lines of each file, all of them when negative")
+		noColor  = flag.Bool("no-color", false, "disable colors in console output")
+		interval = flag.Duration("poll", 250*time.Millisecond, "how often followed files are checked")
+		fields   = fieldFilters{}
+	)
+	flag.Var(fields, "field", "only show entries with field `key=value`, may be repeated")
+	flag.Parse()
+
+	f := &filter{fields: fields}
+	var err error
+	if f.level, err = reader.ParseLevelLabel(*level); err != nil {
+		fatal(err)
+	}
+	if f.since, err = parseTime(*since); err != nil {
+		fatal(err)
+	}
+	if f.until, err = parseTime(*until); err != nil {
+		fatal(err)
+	}
+
+	opts := reader.Options{DataKey: *dataKey}
+	switch *input {
+	case "auto":
+		opts.Format = reader.Auto
+	case "json":
+		opts.Format = reader.JSON
+	case "text":
+		opts.Format = reader.Text
+	default:
+		fatal(fmt.Errorf("unknown input format %q", *input))
+	}
+
+	logger := logy.New()
+	logger.Out = os.Stdout
+	logger.ReportCaller = true
+	p := &printer{out: os.Stdout, logger: logger, filter: f, opts: opts}
+	switch *output {
+	case "console":
+		p.formatter = &logy.ConsoleFormatter{Clock: true, TimestampFormat: "2006-01-02 15:04:05.000", DisableColors: *noColor}
+	case "json":
+		p.formatter = &logy.JSONFormatter{}
+	case "text":
+		p.formatter = &logy.TextFormatter{DisableColors: true}
+	default:
+		fatal(fmt.Errorf("unknown output format %q", *output))
+	}
+
+	if flag.NArg() == 0 {
+		if err := readLines(os.Stdin, p.line); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range flag.Args() {
+		if !*follow {
+			if err := readFile(name, p.line); err != nil {
+				fatal(err)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := followFile(name, *lines, *interval, p.line); err != nil {
+				fatal(err)
+			}
+		}(name)
+	}
+	wg.Wait()
+}
+
+// parseTime accepts RFC 3339 times and durations counted back from now.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad time %q, want RFC 3339 or a duration", s)
+	}
+	return t, nil
+}
+
+func readFile(name string, fn func([]byte)) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return readLines(file, fn)
+}
+
+// readLines calls fn with every line of r, without the line ending.
+func readLines(r io.Reader, fn func([]byte)) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			fn(trimLine(line))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// followFile prints the last lines of name, then the lines appended to it,
+// forever. A missing file is waited for and read from the start. When
+// the file is renamed away by rotation, the rest of the old file is read
+// before switching to the new one; a truncated file is read from the start.
+func followFile(name string, lines int, interval time.Duration, fn func([]byte)) error {
+	file, err := os.Open(name)
+	created := false
+	for os.IsNotExist(err) {
+		// wait for the file to be created
+		time.Sleep(interval)
+		file, err = os.Open(name)
+		created = true
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { file.Close() }()
+
+	var offset int64
+	if !created && lines >= 0 {
+		if offset, err = lastLinesOffset(file, lines); err != nil {
+			return err
+		}
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	br := bufio.NewReader(file)
+	var partial []byte
+	for {
+		line, err := br.ReadBytes('\n')
+		offset += int64(len(line))
+		if err == nil {
+			fn(trimLine(append(partial, line...)))
+			partial = partial[:0]
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		// keep an unterminated line until the rest of it arrives
+		partial = append(partial, line...)
+
+		time.Sleep(interval)
+		current, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		latest, err := os.Stat(name)
+		switch {
+		case err == nil && !os.SameFile(current, latest):
+			// Rotated: drain what was written before the rename, then
+			// switch over.
+			if rest, _ := io.ReadAll(br); len(rest) > 0 {
+				readLines(bytes.NewReader(append(partial, rest...)), fn)
+			} else if len(partial) > 0 {
+				fn(trimLine(partial))
+			}
+			partial = partial[:0]
+			next, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			file.Close()
+			file, br, offset = next, bufio.NewReader(next), 0
+		case current.Size() < offset:
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			br.Reset(file)
+			partial, offset = partial[:0], 0
+		}
+	}
+}
+
+// lastLinesOffset returns the offset the last n lines of file start at. A
+// final unterminated line counts as a line.
+func lastLinesOffset(file *os.File, n int) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if n == 0 {
+		return size, nil
+	}
+
+	buf := make([]byte, 32*1024)
+	// the newline ending the file doesn't start another line
+	end := size - 1
+	count := 0
+	for end > 0 {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] == '\n' {
+				if count++; count == n {
+					return start + int64(i) + 1, nil
+				}
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+func trimLine(line []byte) []byte {
+	return bytes.TrimRight(line, "\r\n")
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "logy: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLastLinesOffset(t *testing.T) {
+	long := strings.Repeat("x", 40*1024)
+	for _, tt := range []struct {
+		content string
+		n       int
+		want    string
+	}{
+		{"a\nb\nc\n", 2, "b\nc\n"},
+		{"a\nb\nc\n", 3, "a\nb\nc\n"},
+		{"a\nb\nc\n", 10, "a\nb\nc\n"},
+		{"a\nb\nc", 1, "c"},
+		{"a\nb\nc\n", 0, ""},
+		{"", 5, ""},
+		{"\n\n", 1, "\n"},
+		{long + "\n" + long + "\n" + "end\n", 2, long + "\nend\n"},
+	} {
+		name := filepath.Join(t.TempDir(), "app.log")
+		if err := os.WriteFile(name, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset, err := lastLinesOffset(file, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Seek(offset, io.SeekStart)
+		rest, _ := io.ReadAll(file)
+		file.Close()
+		if string(rest) != tt.want {
+			t.Errorf("last %d lines of %.20q = %.20q, want %.20q", tt.n, tt.content, rest, tt.want)
+		}
+	}
+}
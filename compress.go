@@ -0,0 +1,124 @@
+package logy
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Compressor compresses rotated log files. RotatingFileWriter runs it in the
+// background once a file has been rotated out.
+type Compressor interface {
+	// Extension is appended to the name of compressed files, e.g. ".gz".
+	Extension() string
+
+	// Compress writes the compressed content of src to dst. name and modTime
+	// describe the original file for formats that record them.
+	Compress(dst io.Writer, src io.Reader, name string, modTime time.Time) error
+}
+
+// GzipCompressor compresses files with gzip. It is the default Compressor.
+type GzipCompressor struct {
+	// Level is the gzip compression level, gzip.DefaultCompression when zero.
+	Level int
+}
+
+func (c *GzipCompressor) Extension() string {
+	return ".gz"
+}
+
+func (c *GzipCompressor) Compress(dst io.Writer, src io.Reader, name string, modTime time.Time) error {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gz, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+	gz.Name = name
+	gz.ModTime = modTime
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
+
+// ZipCompressor stores each file in a zip archive of its own.
+type ZipCompressor struct{}
+
+func (c *ZipCompressor) Extension() string {
+	return ".zip"
+}
+
+func (c *ZipCompressor) Compress(dst io.Writer, src io.Reader, name string, modTime time.Time) error {
+	archive := zip.NewWriter(dst)
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	header.Modified = modTime
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		archive.Close()
+		return err
+	}
+	if _, err := io.Copy(writer, src); err != nil {
+		archive.Close()
+		return err
+	}
+	return archive.Close()
+}
+
+// compressedExtensions are recognised as already compressed regardless of
+// the configured Compressor.
+var compressedExtensions = []string{".gz", ".zip"}
+
+func isCompressed(name string, c Compressor) bool {
+	if c != nil && strings.HasSuffix(name, c.Extension()) {
+		return true
+	}
+	for _, ext := range compressedExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile compresses src next to itself with c. src is only removed
+// after the compressed file has been synced to disk; on failure the partial
+// output is removed instead and src is left alone.
+func compressFile(src string, c Compressor) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst := src + c.Extension()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	err = c.Compress(out, in, filepath.Base(src), info.ModTime())
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	// keep the age of the segment for the retention policy
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
@@ -0,0 +1,72 @@
+package logy
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressOnlyOwnBackups(t *testing.T) {
+	dir := t.TempDir()
+	main := NewRotatingFileWriter(filepath.Join(dir, "%Y%m%d.log"), 10)
+	main.RotateInterval = RotateDaily
+	main.Compress = true
+	errs := NewRotatingFileWriter(filepath.Join(dir, "error-%Y%m%d.log"), 0)
+	errs.RotateInterval = RotateDaily
+	errs.Compress = true
+	defer errs.Close()
+
+	if _, err := errs.Write([]byte("error 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := main.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := main.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := errs.Write([]byte("error 2\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().Format("20060102")
+	data, err := os.ReadFile(filepath.Join(dir, "error-"+today+".log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "error 1\nerror 2\n" {
+		t.Errorf("error file = %q", data)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, today+".log_*.log.gz"))
+	if len(backups) != 1 {
+		t.Fatalf("compressed backups = %v, files = %v", backups, listDir(t, dir))
+	}
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "first\n" {
+		t.Errorf("backup content = %q", content)
+	}
+	for _, name := range listDir(t, dir) {
+		if strings.HasPrefix(name, "error-") && name != "error-"+today+".log" {
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+}
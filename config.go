@@ -0,0 +1,451 @@
+package logy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config describes the setup of a logger. It can be read from a JSON file
+// with LoadConfig and from LOGY_* environment variables with LoadEnv, then
+// applied with Build or Apply. Unset fields mean the defaults of New, except
+// for a nil Level and empty file names, which keep those of the logger.
+type Config struct {
+	// Level is the logger level, e.g. "debug". Nil keeps the current level.
+	Level *Level `json:"level,omitempty"`
+
+	ReportCaller bool `json:"reportCaller,omitempty"`
+
+	Formatter FormatterConfig `json:"formatter"`
+
+	// Output is "stderr" (the default), "stdout" or "file" for the rotating
+	// files described by File.
+	Output string `json:"output,omitempty"`
+
+	File FileConfig `json:"file"`
+
+	// Sinks are outputs added next to Output, see AddSink.
+	Sinks []SinkConfig `json:"sinks,omitempty"`
+}
+
+// FormatterConfig selects a formatter by name and sets its options. Options
+// not supported by the named formatter are ignored.
+type FormatterConfig struct {
+	// Name is "text" (the default), "json" or "console".
+	Name string `json:"name,omitempty"`
+
+	TimestampFormat string `json:"timestampFormat,omitempty"`
+
+	DisableTimestamp bool `json:"disableTimestamp,omitempty"`
+
+	// ForceColors and DisableColors apply to the text and console formatters.
+	ForceColors bool `json:"forceColors,omitempty"`
+
+	DisableColors bool `json:"disableColors,omitempty"`
+
+	// FullTimestamp, PadLevelText and LevelLabelStyle apply to the text
+	// formatter. LevelLabelStyle is "default", "upper", "short" or "letter".
+	FullTimestamp bool `json:"fullTimestamp,omitempty"`
+
+	PadLevelText bool `json:"padLevelText,omitempty"`
+
+	LevelLabelStyle string `json:"levelLabelStyle,omitempty"`
+
+	// DataKey and PrettyPrint apply to the JSON formatter.
+	DataKey string `json:"dataKey,omitempty"`
+
+	PrettyPrint bool `json:"prettyPrint,omitempty"`
+
+	// Clock and MessageWidth apply to the console formatter.
+	Clock bool `json:"clock,omitempty"`
+
+	MessageWidth int `json:"messageWidth,omitempty"`
+}
+
+// FileConfig describes the rotating log files used when Config.Output is
+// "file", see Setifwf.
+type FileConfig struct {
+	// Dir and Name locate the log file, see SetFilepn.
+	Dir string `json:"dir,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	// MaxSize is the size files are rotated at, e.g. "100MB".
+	MaxSize ByteSize `json:"maxSize,omitempty"`
+
+	// RotateInterval is "hourly", "daily" or a duration such as "6h".
+	RotateInterval string `json:"rotateInterval,omitempty"`
+
+	MaxBackups int `json:"maxBackups,omitempty"`
+
+	// MaxAge is a duration such as "72h" or a number of days such as "7d".
+	MaxAge string `json:"maxAge,omitempty"`
+
+	MaxTotalSize ByteSize `json:"maxTotalSize,omitempty"`
+
+	// Compress is "gzip", "zip" or "none".
+	Compress string `json:"compress,omitempty"`
+
+	// LevelFiles routes levels to files of their own, see SetLevelFiles.
+	LevelFiles map[Level]string `json:"levelFiles,omitempty"`
+}
+
+// SinkConfig describes an additional output.
+type SinkConfig struct {
+	// Output is "stderr", "stdout" or the name of a file.
+	Output string `json:"output"`
+
+	// Level is the most verbose level written to the sink. Nil takes every
+	// entry the logger lets through.
+	Level *Level `json:"level,omitempty"`
+
+	// Formatter is the formatter of the logger when its Name is empty.
+	Formatter FormatterConfig `json:"formatter"`
+
+	// MaxSize is the size a file output is rotated at.
+	MaxSize ByteSize `json:"maxSize,omitempty"`
+}
+
+// LoadConfig reads the JSON configuration in filename, then applies the
+// LOGY_* environment variables on top of it. An empty filename reads the
+// environment only.
+func LoadConfig(filename string) (*Config, error) {
+	config := &Config{}
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("can't read config: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", filename, err)
+		}
+	}
+	if err := config.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// configEnv lists the environment variables read by LoadEnv and the setting
+// each one overrides.
+var configEnv = []struct {
+	name string
+	set  func(c *Config, value string) error
+}{
+	{"LOGY_LEVEL", func(c *Config, v string) error {
+		level, err := ParseLevel(v)
+		c.Level = &level
+		return err
+	}},
+	{"LOGY_REPORT_CALLER", func(c *Config, v string) (err error) {
+		c.ReportCaller, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"LOGY_FORMATTER", func(c *Config, v string) error {
+		c.Formatter.Name = v
+		return nil
+	}},
+	{"LOGY_TIMESTAMP_FORMAT", func(c *Config, v string) error {
+		c.Formatter.TimestampFormat = v
+		return nil
+	}},
+	{"LOGY_FORCE_COLORS", func(c *Config, v string) (err error) {
+		c.Formatter.ForceColors, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"LOGY_DISABLE_COLORS", func(c *Config, v string) (err error) {
+		c.Formatter.DisableColors, err = strconv.ParseBool(v)
+		return err
+	}},
+	{"LOGY_OUTPUT", func(c *Config, v string) error {
+		c.Output = v
+		return nil
+	}},
+	{"LOGY_FILE_DIR", func(c *Config, v string) error {
+		c.File.Dir = v
+		return nil
+	}},
+	{"LOGY_FILE_NAME", func(c *Config, v string) error {
+		c.File.Name = v
+		return nil
+	}},
+	{"LOGY_FILE_MAX_SIZE", func(c *Config, v string) (err error) {
+		c.File.MaxSize, err = ParseByteSize(v)
+		return err
+	}},
+	{"LOGY_FILE_ROTATE_INTERVAL", func(c *Config, v string) error {
+		c.File.RotateInterval = v
+		return nil
+	}},
+	{"LOGY_FILE_MAX_BACKUPS", func(c *Config, v string) (err error) {
+		c.File.MaxBackups, err = strconv.Atoi(v)
+		return err
+	}},
+	{"LOGY_FILE_MAX_AGE", func(c *Config, v string) error {
+		c.File.MaxAge = v
+		return nil
+	}},
+	{"LOGY_FILE_MAX_TOTAL_SIZE", func(c *Config, v string) (err error) {
+		c.File.MaxTotalSize, err = ParseByteSize(v)
+		return err
+	}},
+	{"LOGY_FILE_COMPRESS", func(c *Config, v string) error {
+		c.File.Compress = v
+		return nil
+	}},
+}
+
+// LoadEnv overrides the settings of c with the LOGY_* environment variables
+// that are set: LOGY_LEVEL, LOGY_REPORT_CALLER, LOGY_FORMATTER,
+// LOGY_TIMESTAMP_FORMAT, LOGY_FORCE_COLORS, LOGY_DISABLE_COLORS, LOGY_OUTPUT
+// and LOGY_FILE_DIR, LOGY_FILE_NAME, LOGY_FILE_MAX_SIZE,
+// LOGY_FILE_ROTATE_INTERVAL, LOGY_FILE_MAX_BACKUPS, LOGY_FILE_MAX_AGE,
+// LOGY_FILE_MAX_TOTAL_SIZE and LOGY_FILE_COMPRESS for the file output.
+func (c *Config) LoadEnv() error {
+	for _, env := range configEnv {
+		value, ok := os.LookupEnv(env.name)
+		if !ok {
+			continue
+		}
+		if err := env.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", env.name, err)
+		}
+	}
+	return nil
+}
+
+// Build returns a new logger set up according to c.
+func (c *Config) Build() (*Logger, error) {
+	logger := New()
+	if err := c.Apply(logger); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+// Apply sets logger up according to c. The whole configuration is checked
+// first, the logger is left untouched when it is invalid. Sinks are added to
+// those the logger already has.
+func (c *Config) Apply(logger *Logger) error {
+	formatter, err := c.Formatter.build()
+	if err != nil {
+		return fmt.Errorf("invalid formatter: %w", err)
+	}
+
+	var out io.Writer
+	var file fileSettings
+	switch strings.ToLower(c.Output) {
+	case "", "stderr":
+		out = os.Stderr
+	case "stdout":
+		out = os.Stdout
+	case "file":
+		if file, err = c.File.parse(); err != nil {
+			return fmt.Errorf("invalid file output: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid output %q, want stderr, stdout or file", c.Output)
+	}
+
+	sinks := make([]Sink, 0, len(c.Sinks))
+	for i, sc := range c.Sinks {
+		sink, err := sc.build()
+		if err != nil {
+			return fmt.Errorf("invalid sink %d: %w", i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if c.Level != nil {
+		logger.SetLevel(*c.Level)
+	}
+	logger.SetReportCaller(c.ReportCaller)
+	logger.SetFormatter(formatter)
+	if out != nil {
+		logger.Setifwf(false)
+		logger.SetOutput(out)
+	} else {
+		file.apply(logger, c.File)
+		logger.Setifwf(true)
+	}
+	for _, sink := range sinks {
+		if err := logger.AddSink(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c FormatterConfig) build() (Formatter, error) {
+	switch strings.ToLower(c.Name) {
+	case "", "text":
+		style, err := parseLevelLabelStyle(c.LevelLabelStyle)
+		if err != nil {
+			return nil, err
+		}
+		return &TextFormatter{
+			ForceColors:      c.ForceColors,
+			DisableColors:    c.DisableColors,
+			DisableTimestamp: c.DisableTimestamp,
+			FullTimestamp:    c.FullTimestamp,
+			TimestampFormat:  c.TimestampFormat,
+			PadLevelText:     c.PadLevelText,
+			LevelLabelStyle:  style,
+		}, nil
+	case "json":
+		return &JSONFormatter{
+			TimestampFormat:  c.TimestampFormat,
+			DisableTimestamp: c.DisableTimestamp,
+			DataKey:          c.DataKey,
+			PrettyPrint:      c.PrettyPrint,
+		}, nil
+	case "console":
+		return &ConsoleFormatter{
+			ForceColors:      c.ForceColors,
+			DisableColors:    c.DisableColors,
+			DisableTimestamp: c.DisableTimestamp,
+			Clock:            c.Clock,
+			TimestampFormat:  c.TimestampFormat,
+			MessageWidth:     c.MessageWidth,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown formatter %q, want text, json or console", c.Name)
+}
+
+func parseLevelLabelStyle(s string) (LevelLabelStyle, error) {
+	switch strings.ToLower(s) {
+	case "", "default":
+		return LevelLabelDefault, nil
+	case "upper":
+		return LevelLabelUpper, nil
+	case "short":
+		return LevelLabelShort, nil
+	case "letter":
+		return LevelLabelLetter, nil
+	}
+	return 0, fmt.Errorf("unknown level label style %q, want default, upper, short or letter", s)
+}
+
+// fileSettings holds the parsed values of a FileConfig.
+type fileSettings struct {
+	rotateInterval time.Duration
+	maxAge         time.Duration
+	compressor     Compressor
+	setCompressor  bool
+}
+
+func (c FileConfig) parse() (fileSettings, error) {
+	var s fileSettings
+	var err error
+	switch strings.ToLower(c.RotateInterval) {
+	case "":
+	case "hourly":
+		s.rotateInterval = RotateHourly
+	case "daily":
+		s.rotateInterval = RotateDaily
+	default:
+		if s.rotateInterval, err = parseConfigDuration(c.RotateInterval); err != nil {
+			return s, fmt.Errorf("invalid rotateInterval: %w", err)
+		}
+	}
+	if c.MaxAge != "" {
+		if s.maxAge, err = parseConfigDuration(c.MaxAge); err != nil {
+			return s, fmt.Errorf("invalid maxAge: %w", err)
+		}
+	}
+	switch strings.ToLower(c.Compress) {
+	case "":
+	case "gzip":
+		s.compressor, s.setCompressor = &GzipCompressor{}, true
+	case "zip":
+		s.compressor, s.setCompressor = &ZipCompressor{}, true
+	case "none":
+		s.setCompressor = true
+	default:
+		return s, fmt.Errorf("unknown compression %q, want gzip, zip or none", c.Compress)
+	}
+	if c.MaxSize < 0 || c.MaxTotalSize < 0 || c.MaxBackups < 0 {
+		return s, fmt.Errorf("maxSize, maxBackups and maxTotalSize must not be negative")
+	}
+	return s, nil
+}
+
+// apply sets the file output settings of logger. Empty names and a zero
+// MaxSize keep the current ones.
+func (s fileSettings) apply(logger *Logger, c FileConfig) {
+	logger.mu.Lock()
+	dir, name := logger.fp, logger.fn
+	logger.mu.Unlock()
+	if c.Dir != "" {
+		dir = c.Dir
+	}
+	if c.Name != "" {
+		name = c.Name
+	}
+	logger.SetFilepn(name, dir)
+	if c.MaxSize > 0 {
+		logger.SetmaxFileSize(int64(c.MaxSize))
+	}
+	logger.SetRotateInterval(s.rotateInterval, nil)
+	logger.SetRetention(c.MaxBackups, s.maxAge, int64(c.MaxTotalSize))
+	if s.setCompressor {
+		logger.SetCompressor(s.compressor)
+	}
+	if c.LevelFiles != nil {
+		logger.SetLevelFiles(c.LevelFiles)
+	}
+}
+
+// parseConfigDuration parses a duration, also accepting a number of days
+// such as "7d".
+func parseConfigDuration(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("invalid duration %q", s)
+	}
+	return d, err
+}
+
+func (c SinkConfig) build() (Sink, error) {
+	var formatter Formatter
+	if c.Formatter.Name != "" {
+		var err error
+		if formatter, err = c.Formatter.build(); err != nil {
+			return nil, fmt.Errorf("invalid formatter: %w", err)
+		}
+	}
+	level := TraceLevel
+	if c.Level != nil {
+		level = *c.Level
+	}
+
+	var out io.Writer
+	switch strings.ToLower(c.Output) {
+	case "":
+		return nil, fmt.Errorf("missing output")
+	case "stderr":
+		out = os.Stderr
+	case "stdout":
+		out = os.Stdout
+	default:
+		if c.MaxSize < 0 {
+			return nil, fmt.Errorf("negative maxSize")
+		}
+		out = NewRotatingFileWriter(c.Output, int64(c.MaxSize))
+	}
+	return NewWriterSink(out, formatter, level), nil
+}
@@ -0,0 +1,276 @@
+package logy
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultConsoleTimeFormat = "15:04:05.000"
+	defaultMessageWidth      = 40
+
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+)
+
+// ConsoleFormatter formats logs for humans reading them during development:
+//
+//	+12.345s INF listening on port               addr=:8080 tls=false
+//
+// The time is relative to the start of the program, or a short clock with
+// Clock set, the level sits in a fixed-width column and the message is bold,
+// followed by the dimmed fields. Multi-line values, such as error stacks,
+// are printed indented below the entry. time.Duration and ByteSize values
+// are shown in human units.
+type ConsoleFormatter struct {
+	// Set to true to bypass checking for a TTY before outputting colors.
+	ForceColors bool
+
+	// Force disabling colors.
+	DisableColors bool
+
+	DisableTimestamp bool
+
+	// Clock prints the time of day instead of the time since start.
+	Clock bool
+
+	// TimestampFormat is used with Clock, "15:04:05.000" by default.
+	TimestampFormat string
+
+	// MessageWidth pads messages so that fields line up, 40 by default.
+	// Negative disables padding.
+	MessageWidth int
+
+	// LevelLabels overrides the three-letter names of the levels it
+	// contains.
+	LevelLabels map[Level]string
+
+	CallerPrettyfier func(*runtime.Frame) (function string, file string)
+
+	initOnce           sync.Once
+	colors             colorChoice
+	levelTextMaxLength int
+}
+
+func (f *ConsoleFormatter) init(entry *Entry) {
+	switch {
+	case f.DisableColors:
+		f.colors = colorNever
+	case f.ForceColors:
+		f.colors = colorAlways
+	default:
+		f.colors = envColors()
+	}
+	for _, level := range AllLevels {
+		if n := utf8.RuneCountInString(f.levelLabel(level)); n > f.levelTextMaxLength {
+			f.levelTextMaxLength = n
+		}
+	}
+}
+
+func (f *ConsoleFormatter) levelLabel(level Level) string {
+	if label, ok := f.LevelLabels[level]; ok {
+		return label
+	}
+	if label, ok := shortLevelLabels[level]; ok {
+		return label
+	}
+	return strings.ToUpper(level.String())
+}
+
+// isColored reports whether the entry gets colors, deciding from the
+// output it is written to unless the options or environment settled it.
+func (f *ConsoleFormatter) isColored(entry *Entry) bool {
+	switch f.colors {
+	case colorNever:
+		return false
+	case colorAlways:
+		return true
+	}
+	return entry.isTerminal()
+}
+
+// Format renders a single log entry
+func (f *ConsoleFormatter) Format(entry *Entry) ([]byte, error) {
+	f.initOnce.Do(func() { f.init(entry) })
+	colored := f.isColored(entry)
+
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	if !f.DisableTimestamp {
+		style(b, colored, ansiDim, f.timestamp(entry))
+		b.WriteByte(' ')
+	}
+
+	levelText := f.levelLabel(entry.Level)
+	if n := f.levelTextMaxLength - utf8.RuneCountInString(levelText); n > 0 {
+		levelText += strings.Repeat(" ", n)
+	}
+	style(b, colored, fmt.Sprintf("\x1b[%dm", levelColor(entry.Level)), levelText)
+	b.WriteByte(' ')
+
+	message := strings.TrimSuffix(entry.Message, "\n")
+	width := f.MessageWidth
+	if width == 0 {
+		width = defaultMessageWidth
+	}
+	if n := width - utf8.RuneCountInString(message); n > 0 {
+		message += strings.Repeat(" ", n)
+	}
+	style(b, colored, ansiBold, message)
+
+	data := make(Fields, len(entry.Data)+2)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	if entry.err != "" {
+		data[FieldKeyLogrusError] = entry.err
+	}
+	if entry.HasCaller() {
+		funcVal := entry.Caller.Function
+		fileVal := fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+		if f.CallerPrettyfier != nil {
+			funcVal, fileVal = f.CallerPrettyfier(entry.Caller)
+		}
+		if fileVal != "" {
+			data[FieldKeyFile] = fileVal
+		}
+		if funcVal != "" {
+			data[FieldKeyFunc] = funcVal
+		}
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Multi-line values go below the entry so they don't break the columns.
+	var blocks []string
+	for _, k := range keys {
+		value := consoleValue(data[k])
+		if strings.Contains(value, "\n") {
+			blocks = append(blocks, k)
+			continue
+		}
+		if needsConsoleQuoting(value) {
+			value = fmt.Sprintf("%q", value)
+		}
+		b.WriteByte(' ')
+		style(b, colored, ansiDim, k+"="+value)
+	}
+	for _, k := range blocks {
+		b.WriteString("\n    ")
+		style(b, colored, ansiDim, k+":")
+		for _, line := range strings.Split(strings.TrimRight(consoleValue(data[k]), "\n"), "\n") {
+			b.WriteString("\n        ")
+			b.WriteString(line)
+		}
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func (f *ConsoleFormatter) timestamp(entry *Entry) string {
+	if f.Clock {
+		format := f.TimestampFormat
+		if format == "" {
+			format = defaultConsoleTimeFormat
+		}
+		return entry.Time.Format(format)
+	}
+	elapsed := entry.Time.Sub(baseTimestamp)
+	return fmt.Sprintf("%+9.3fs", elapsed.Seconds())
+}
+
+// style writes s wrapped in the given ANSI escape when colored.
+func style(b *bytes.Buffer, colored bool, escape, s string) {
+	if !colored {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(escape)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
+
+func levelColor(level Level) int {
+	switch level {
+	case DebugLevel, TraceLevel:
+		return gray
+	case WarnLevel:
+		return yellow
+	case ErrorLevel, FatalLevel, PanicLevel:
+		return red
+	default:
+		return blue
+	}
+}
+
+// consoleValue renders a field value, using human units for durations and
+// byte sizes and the detailed form of errors, which may carry a stack.
+func consoleValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Duration:
+		return humanDuration(v)
+	case ByteSize:
+		return v.String()
+	case error:
+		if detailed := fmt.Sprintf("%+v", v); detailed != "" {
+			return detailed
+		}
+		return v.Error()
+	}
+	return fmt.Sprint(v)
+}
+
+// humanDuration rounds d to three significant digits, e.g. 1.23s or 45.7ms.
+func humanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= 100*time.Second:
+		return d.Round(time.Second).String()
+	case abs >= 10*time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	case abs >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case abs >= 100*time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	case abs >= 10*time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	case abs >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.String()
+}
+
+func needsConsoleQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, ch := range s {
+		if ch <= ' ' || ch == '"' || ch == '=' || ch == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package logy
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorEnvironment(t *testing.T) {
+	for _, tt := range []struct {
+		noColor, forceColor string
+		colored             bool
+	}{
+		{"", "", false},
+		{"", "1", true},
+		{"", "true", true},
+		{"", "0", false},
+		{"", "false", false},
+		{"1", "1", false},
+	} {
+		for _, newFormatter := range []func() Formatter{
+			func() Formatter { return &TextFormatter{} },
+			func() Formatter { return &ConsoleFormatter{} },
+		} {
+			setenv(t, "NO_COLOR", tt.noColor)
+			setenv(t, "FORCE_COLOR", tt.forceColor)
+			var buf bytes.Buffer
+			l := New()
+			l.SetOutput(&buf)
+			l.SetFormatter(newFormatter())
+			l.Info("hello")
+
+			if colored := strings.Contains(buf.String(), "\x1b["); colored != tt.colored {
+				t.Errorf("%T with NO_COLOR=%q FORCE_COLOR=%q: colored = %v, want %v",
+					l.Formatter, tt.noColor, tt.forceColor, colored, tt.colored)
+			}
+		}
+	}
+}
+
+// setenv sets key to value for the duration of the test, unsetting it for an
+// empty value.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	old, ok := os.LookupEnv(key)
+	if value == "" {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, value)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
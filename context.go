@@ -0,0 +1,107 @@
+package logy
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// ContextExtractor returns the fields to add to an entry logged with the
+// given context, e.g. a request or trace ID stored in it. It must be safe
+// for concurrent use.
+type ContextExtractor func(ctx context.Context) Fields
+
+// ContextValueExtractor returns an extractor adding ctx.Value(key), when
+// present, as the field named field.
+func ContextValueExtractor(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context) Fields {
+		if v := ctx.Value(key); v != nil {
+			return Fields{field: v}
+		}
+		return nil
+	}
+}
+
+// PprofLabelsExtractor adds the runtime/pprof labels of the context, as set
+// by pprof.Do or pprof.WithLabels, as fields.
+func PprofLabelsExtractor(ctx context.Context) Fields {
+	var fields Fields
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if fields == nil {
+			fields = make(Fields)
+		}
+		fields[key] = value
+		return true
+	})
+	return fields
+}
+
+// AddContextExtractor registers an extractor run for every entry carrying
+// a context, see WithContext. Fields set explicitly on the entry take
+// precedence over extracted ones.
+func (logger *Logger) AddContextExtractor(extractor ContextExtractor) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.contextExtractors = append(logger.contextExtractors, extractor)
+}
+
+// extractContext adds the fields the extractors find in the entry context.
+func (entry *Entry) extractContext(extractors []ContextExtractor) {
+	if entry.Context == nil {
+		return
+	}
+	for _, extractor := range extractors {
+		for k, v := range extractor(entry.Context) {
+			if _, ok := entry.Data[k]; !ok {
+				entry.Data[k] = v
+			}
+		}
+	}
+}
+
+type contextKey int
+
+const (
+	entryContextKey contextKey = iota
+	fieldsContextKey
+)
+
+// NewContext returns a copy of ctx carrying entry, to be retrieved with
+// FromContext.
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, entryContextKey, entry)
+}
+
+// FromContext returns the entry stored in ctx by NewContext, or a new entry
+// of the standard logger, bound to ctx as with WithContext.
+func FromContext(ctx context.Context) *Entry {
+	entry, ok := ctx.Value(entryContextKey).(*Entry)
+	if !ok || entry == nil {
+		entry = NewEntry(StandardLogger())
+	}
+	return entry.WithContext(ctx)
+}
+
+// ContextWithFields returns a copy of ctx carrying fields, in addition to
+// those already attached to it. They are added to the entries created with
+// WithContext(ctx), except where the entry already has a field of the same
+// name.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	parent := fieldsFromContext(ctx)
+	merged := make(Fields, len(parent)+len(fields))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+func fieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey).(Fields)
+	return fields
+}
@@ -0,0 +1,196 @@
+package logy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keys of the fields added to "repeated N times" summaries.
+var (
+	RepeatedKey  = "repeated"
+	FirstSeenKey = "first_seen"
+	LastSeenKey  = "last_seen"
+)
+
+// deduper suppresses entries identical to one logged less than window ago.
+// Once the window of an entry is over, a summary telling how often it was
+// suppressed is logged.
+type deduper struct {
+	logger *Logger
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*dedupState
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+type dedupState struct {
+	entry       *Entry
+	suppressed  int
+	first, last time.Time
+	expires     time.Time
+}
+
+func newDeduper(logger *Logger, window time.Duration) *deduper {
+	d := &deduper{
+		logger:  logger,
+		window:  window,
+		pending: make(map[string]*dedupState),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+func (d *deduper) run() {
+	defer close(d.stopped)
+	ticker := time.NewTicker(d.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.emit(d.expired(time.Now(), false))
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// admit reports whether entry should be written, counting it when it
+// repeats an entry still inside its window.
+func (d *deduper) admit(entry *Entry) bool {
+	if entry.Level <= FatalLevel {
+		return true
+	}
+	key := dedupKey(entry)
+	now := time.Now()
+
+	d.mu.Lock()
+	state, ok := d.pending[key]
+	if ok && now.Before(state.expires) {
+		state.suppressed++
+		state.last = now
+		d.mu.Unlock()
+		return false
+	}
+	var summary *Entry
+	if ok {
+		summary = state.summary()
+	}
+	first := entry.Dup()
+	first.Level, first.Message, first.Caller = entry.Level, entry.Message, entry.Caller
+	d.pending[key] = &dedupState{entry: first, first: now, last: now, expires: now.Add(d.window)}
+	d.mu.Unlock()
+
+	if summary != nil {
+		d.emit([]*Entry{summary})
+	}
+	return true
+}
+
+// expired removes the states whose window is over at now, or all of them
+// with all set, and returns the summaries to log for them.
+func (d *deduper) expired(now time.Time, all bool) []*Entry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var summaries []*Entry
+	for key, state := range d.pending {
+		if !all && now.Before(state.expires) {
+			continue
+		}
+		delete(d.pending, key)
+		if summary := state.summary(); summary != nil {
+			summaries = append(summaries, summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Time.Before(summaries[j].Time)
+	})
+	return summaries
+}
+
+// summary returns the entry reporting the suppressed repeats, or nil if
+// there were none.
+func (s *dedupState) summary() *Entry {
+	if s.suppressed == 0 {
+		return nil
+	}
+	summary := s.entry.Dup()
+	summary.Level = s.entry.Level
+	summary.Caller = s.entry.Caller
+	summary.Time = s.last
+	summary.Message = fmt.Sprintf("%s (repeated %d times)", s.entry.Message, s.suppressed)
+	summary.Data[RepeatedKey] = s.suppressed
+	summary.Data[FirstSeenKey] = s.first.Format(time.RFC3339Nano)
+	summary.Data[LastSeenKey] = s.last.Format(time.RFC3339Nano)
+	return summary
+}
+
+// emit writes the summaries, bypassing sampling, hooks and deduplication.
+func (d *deduper) emit(summaries []*Entry) {
+	if len(summaries) == 0 {
+		return
+	}
+	d.logger.mu.Lock()
+	bufPool := summaries[0].getBufferPool()
+	queue := d.logger.async
+	d.logger.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary.dispatch(queue, bufPool)
+	}
+}
+
+// flush logs the summaries of every pending entry.
+func (d *deduper) flush() {
+	d.emit(d.expired(time.Now(), true))
+}
+
+// close stops the background goroutine and flushes pending summaries.
+func (d *deduper) close() {
+	close(d.stop)
+	<-d.stopped
+	d.flush()
+}
+
+// dedupKey identifies entries with the same level, message and fields.
+func dedupKey(entry *Entry) string {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\x00%s", entry.Level, entry.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%v", k, entry.Data[k])
+	}
+	return b.String()
+}
+
+// SetDeduplication merges identical entries, same level, message and
+// fields, logged within window of the first one: the first is written, the
+// repeats are counted and a "repeated N times" summary follows once the
+// window is over. Zero turns it off, flushing pending summaries.
+func (logger *Logger) SetDeduplication(window time.Duration) {
+	logger.own()
+	var d *deduper
+	if window > 0 {
+		d = newDeduper(logger, window)
+	}
+	logger.mu.Lock()
+	old := logger.dedup
+	logger.dedup = d
+	logger.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
@@ -0,0 +1,57 @@
+package logy
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupSummaryOnClose(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		var buf bytes.Buffer
+		logger := New()
+		logger.Out = &buf
+		logger.Formatter = &JSONFormatter{}
+		logger.SetDeduplication(time.Hour)
+		if async {
+			logger.SetAsync(AsyncOptions{})
+		}
+
+		for i := 0; i < 5; i++ {
+			logger.WithField("id", 1).Warn("disk full")
+		}
+		logger.WithField("id", 2).Warn("disk full")
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("async=%v: got %d lines, want 3:\n%s", async, len(lines), buf.String())
+		}
+		var summary map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+			t.Fatal(err)
+		}
+		if summary["msg"] != "disk full (repeated 4 times)" || summary[RepeatedKey] != float64(4) || summary["id"] != float64(1) {
+			t.Errorf("async=%v: summary = %v", async, summary)
+		}
+		if summary[FirstSeenKey] == nil || summary[LastSeenKey] == nil {
+			t.Errorf("async=%v: summary lacks first and last seen times: %v", async, summary)
+		}
+	}
+}
+
+func TestDedupNoSummaryWithoutRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.Out = &buf
+	logger.SetDeduplication(time.Hour)
+	logger.Info("once")
+	logger.Close()
+	if strings.Contains(buf.String(), "repeated") || strings.Count(buf.String(), "once") != 1 {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
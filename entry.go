@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"path"
 	"reflect"
 	"runtime"
 	"strings"
@@ -56,6 +56,10 @@ type Entry struct {
 	Context context.Context
 
 	err string
+
+	// out is the writer the entry is being formatted for, Logger.Out when
+	// nil.
+	out io.Writer
 }
 
 func NewEntry(logger *Logger) *Entry {
@@ -97,7 +101,11 @@ func (entry *Entry) WithError(err error) *Entry {
 
 // Add a context to the Entry.
 func (entry *Entry) WithContext(ctx context.Context) *Entry {
-	dataCopy := make(Fields, len(entry.Data))
+	ctxFields := fieldsFromContext(ctx)
+	dataCopy := make(Fields, len(entry.Data)+len(ctxFields))
+	for k, v := range ctxFields {
+		dataCopy[k] = v
+	}
 	for k, v := range entry.Data {
 		dataCopy[k] = v
 	}
@@ -207,8 +215,6 @@ func (entry Entry) HasCaller() (has bool) {
 }
 
 func (entry *Entry) log(level Level, msg string) {
-	var buffer *bytes.Buffer
-
 	newEntry := entry.Dup()
 
 	if newEntry.Time.IsZero() {
@@ -218,31 +224,82 @@ func (entry *Entry) log(level Level, msg string) {
 	newEntry.Level = level
 	newEntry.Message = msg
 
+	if logger := newEntry.Logger; logger.parent != nil {
+		if _, ok := newEntry.Data[LoggerKey]; !ok {
+			newEntry.Data[LoggerKey] = logger.name
+		}
+		newEntry.Logger = logger.output()
+	}
+
 	newEntry.Logger.mu.Lock()
 	reportCaller := newEntry.Logger.ReportCaller
 	bufPool := newEntry.getBufferPool()
+	queue := newEntry.Logger.async
+	sampler := newEntry.Logger.samplerFor(level)
+	dedup := newEntry.Logger.dedup
+	extractors := newEntry.Logger.contextExtractors
 	newEntry.Logger.mu.Unlock()
 
+	newEntry.extractContext(extractors)
+
+	if sampler != nil && !sampler.Sample(newEntry) {
+		return
+	}
+
 	if reportCaller {
 		newEntry.Caller = getCaller()
 	}
 
-	//newEntry.fireHooks()
-	buffer = bufPool.Get()
+	newEntry.fireHooks()
+
+	if dedup == nil || dedup.admit(newEntry) {
+		newEntry.dispatch(queue, bufPool)
+	}
+
+	if level <= PanicLevel {
+		newEntry.Logger.Sync()
+		panic(newEntry)
+	}
+}
+
+// dispatch hands the entry over to queue in asynchronous mode, which owns it
+// from then on, or else writes it right away.
+func (entry *Entry) dispatch(queue *asyncQueue, bufPool BufferPool) {
+	if queue == nil || !queue.enqueue(entry) {
+		entry.writeBuffered(bufPool)
+	}
+}
+
+// writeBuffered writes the entry using a buffer from bufPool.
+func (entry *Entry) writeBuffered(bufPool BufferPool) {
+	buffer := bufPool.Get()
 	defer func() {
-		newEntry.Buffer = nil
+		entry.Buffer = nil
 		buffer.Reset()
 		bufPool.Put(buffer)
 	}()
 	buffer.Reset()
-	newEntry.Buffer = buffer
+	entry.Buffer = buffer
 
-	newEntry.write()
-
-	newEntry.Buffer = nil
+	entry.write()
+}
 
-	if level <= PanicLevel {
-		panic(newEntry)
+// fireHooks runs the hooks registered for the entry level. A failing hook is
+// reported on its own and never prevents the entry from being written.
+func (entry *Entry) fireHooks() {
+	entry.Logger.mu.Lock()
+	hooks := append([]Hook(nil), entry.Logger.Hooks[entry.Level]...)
+	onError := entry.Logger.HookErrorHandler
+	entry.Logger.mu.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook.Fire(entry); err != nil {
+			if onError != nil {
+				onError(hook, entry, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+			}
+		}
 	}
 }
 
@@ -257,41 +314,30 @@ func (entry *Entry) getBufferPool() (pool BufferPool) {
 func (entry *Entry) write() {
 	entry.Logger.mu.Lock()
 	defer entry.Logger.mu.Unlock()
+	entry.out = entry.Logger.Out
 	serialized, err := entry.Logger.Formatter.Format(entry)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
-		return
-	}
-
-	if entry.Logger.IfwFile {
-		fullPath := path.Join(entry.Logger.fp, entry.Logger.fn)
-		file, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err == nil {
-			entry.Logger.Out = file
+	} else {
+		if lw, ok := entry.Logger.Out.(LevelWriter); ok {
+			_, err = lw.WriteLevel(entry.Level, serialized)
 		} else {
-			fmt.Printf("open logFile failed,Err:%v", err)
-			return
+			_, err = entry.Logger.Out.Write(serialized)
 		}
-		errFileObj, err := os.OpenFile("error-"+fullPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 		if err != nil {
-			fmt.Printf("open errorLogFile failed,Err:%v", err)
-			return
-		}
-		// 将文件句柄赋值给结构体中的元素
-		entry.Logger.fileObj = file
-		entry.Logger.errFileObj = errFileObj
-	
-		if entry.Logger.checkLogSize(entry.Logger.fileObj) {
-			fileobj, err := entry.Logger.cutFile(entry.Logger.fileObj)
-			if err != nil {
-				fmt.Printf("log cut failed,err:%v\n", err)
-			}
-			entry.Logger.fileObj = fileobj
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
 		}
 	}
-	if _, err := entry.Logger.Out.Write(serialized); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+	entry.writeSinks(serialized)
+}
+
+// isTerminal reports whether the entry is being written to a terminal.
+func (entry *Entry) isTerminal() bool {
+	out := entry.out
+	if out == nil && entry.Logger != nil {
+		out = entry.Logger.Out
 	}
+	return checkIfTerminal(out)
 }
 
 // Log will log a message at the level given as parameter.
@@ -338,4 +384,108 @@ func (entry *Entry) Fatal(args ...interface{}) {
 
 func (entry *Entry) Panic(args ...interface{}) {
 	entry.Log(PanicLevel, args...)
-}
\ No newline at end of file
+}
+
+// FatalWithCode logs a message at level Fatal then exits with the given
+// status code.
+func (entry *Entry) FatalWithCode(code int, args ...interface{}) {
+	entry.Log(FatalLevel, args...)
+	entry.Logger.Exit(code)
+}
+
+func (entry *Entry) Logf(level Level, format string, args ...interface{}) {
+	if entry.Logger.IsLevelEnabled(level) {
+		entry.Log(level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (entry *Entry) Tracef(format string, args ...interface{}) {
+	entry.Logf(TraceLevel, format, args...)
+}
+
+func (entry *Entry) Debugf(format string, args ...interface{}) {
+	entry.Logf(DebugLevel, format, args...)
+}
+
+func (entry *Entry) Infof(format string, args ...interface{}) {
+	entry.Logf(InfoLevel, format, args...)
+}
+
+func (entry *Entry) Printf(format string, args ...interface{}) {
+	entry.Infof(format, args...)
+}
+
+func (entry *Entry) Warnf(format string, args ...interface{}) {
+	entry.Logf(WarnLevel, format, args...)
+}
+
+func (entry *Entry) Warningf(format string, args ...interface{}) {
+	entry.Warnf(format, args...)
+}
+
+func (entry *Entry) Errorf(format string, args ...interface{}) {
+	entry.Logf(ErrorLevel, format, args...)
+}
+
+func (entry *Entry) Fatalf(format string, args ...interface{}) {
+	entry.Logf(FatalLevel, format, args...)
+	entry.Logger.Exit(1)
+}
+
+func (entry *Entry) Panicf(format string, args ...interface{}) {
+	entry.Logf(PanicLevel, format, args...)
+}
+
+// Entry Println family functions
+
+func (entry *Entry) Logln(level Level, args ...interface{}) {
+	if entry.Logger.IsLevelEnabled(level) {
+		entry.Log(level, entry.sprintlnn(args...))
+	}
+}
+
+func (entry *Entry) Traceln(args ...interface{}) {
+	entry.Logln(TraceLevel, args...)
+}
+
+func (entry *Entry) Debugln(args ...interface{}) {
+	entry.Logln(DebugLevel, args...)
+}
+
+func (entry *Entry) Infoln(args ...interface{}) {
+	entry.Logln(InfoLevel, args...)
+}
+
+func (entry *Entry) Println(args ...interface{}) {
+	entry.Infoln(args...)
+}
+
+func (entry *Entry) Warnln(args ...interface{}) {
+	entry.Logln(WarnLevel, args...)
+}
+
+func (entry *Entry) Warningln(args ...interface{}) {
+	entry.Warnln(args...)
+}
+
+func (entry *Entry) Errorln(args ...interface{}) {
+	entry.Logln(ErrorLevel, args...)
+}
+
+func (entry *Entry) Fatalln(args ...interface{}) {
+	entry.Logln(FatalLevel, args...)
+	entry.Logger.Exit(1)
+}
+
+func (entry *Entry) Panicln(args ...interface{}) {
+	entry.Logln(PanicLevel, args...)
+}
+
+// Sprintlnn => Sprint no newline. This is to get the behavior of how
+// fmt.Sprintln where spaces are always added between operands, regardless of
+// their type. Instead of vendoring the Sprintln implementation to spare a
+// string allocation, we do the simplest thing.
+func (entry *Entry) sprintlnn(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
@@ -0,0 +1,36 @@
+package logy
+
+// A hook to be fired when logging on the logging levels returned from
+// `Levels()` on your implementation of the interface. Hooks run after the
+// entry is built and before it is written, so they may add fields to it or
+// send it somewhere else. Note that this is not fired in a goroutine or a
+// channel with workers, you should handle such functionality yourself if your
+// call is non-blocking and you don't wish for the logging calls for levels
+// returned from `Levels()` to block.
+type Hook interface {
+	Levels() []Level
+	Fire(*Entry) error
+}
+
+// Internal type for storing the hooks on a logger instance.
+type LevelHooks map[Level][]Hook
+
+// Add a hook to an instance of logger. This is called with
+// `log.Hooks.Add(new(MyHook))` where `MyHook` implements the `Hook` interface.
+func (hooks LevelHooks) Add(hook Hook) {
+	for _, level := range hook.Levels() {
+		hooks[level] = append(hooks[level], hook)
+	}
+}
+
+// Fire all the hooks for the passed level. Every hook is fired even if an
+// earlier one fails; the first error is returned.
+func (hooks LevelHooks) Fire(level Level, entry *Entry) error {
+	var first error
+	for _, hook := range hooks[level] {
+		if err := hook.Fire(entry); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -5,12 +5,11 @@ import (
 	"fmt"
 
 	//"io"
-	//"os"
 	"runtime"
 	"sort"
 
 	//"strconv"
-	//"strings"
+	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -25,12 +24,43 @@ const (
 
 var baseTimestamp time.Time
 
+// LevelLabelStyle selects how TextFormatter prints level names.
+type LevelLabelStyle int
+
+const (
+	// LevelLabelDefault prints the lowercase level name, e.g. "info", and
+	// the upper-case name cut to four letters in colored output.
+	LevelLabelDefault LevelLabelStyle = iota
+	// LevelLabelUpper prints the upper-case level name, e.g. "INFO".
+	LevelLabelUpper
+	// LevelLabelShort prints a three-letter abbreviation, e.g. "INF".
+	LevelLabelShort
+	// LevelLabelLetter prints the first letter of the level, e.g. "I".
+	LevelLabelLetter
+)
+
+var shortLevelLabels = map[Level]string{
+	PanicLevel: "PNC",
+	FatalLevel: "FTL",
+	ErrorLevel: "ERR",
+	WarnLevel:  "WRN",
+	InfoLevel:  "INF",
+	DebugLevel: "DBG",
+	TraceLevel: "TRC",
+}
+
 func init() {
 	baseTimestamp = time.Now()
 }
 
 // TextFormatter formats logs into text
 type TextFormatter struct {
+	// Set to true to bypass checking for a TTY before outputting colors.
+	ForceColors bool
+
+	// Force disabling colors.
+	DisableColors bool
+
 	ForceQuote bool
 
 	DisableQuote bool
@@ -45,15 +75,27 @@ type TextFormatter struct {
 
 	SortingFunc func([]string)
 
+	// Disables the truncation of the level text to 4 characters in colored
+	// output.
 	DisableLevelTruncation bool
 
+	// PadLevelText adds padding to the level text so that all the levels
+	// output at the same length. PadLevelText is a superset of the
+	// DisableLevelTruncation option
 	PadLevelText bool
 
+	// LevelLabelStyle selects how level names are printed.
+	LevelLabelStyle LevelLabelStyle
+
+	// LevelLabels overrides the printed name of the levels it contains,
+	// whatever the LevelLabelStyle.
+	LevelLabels map[Level]string
+
 	// QuoteEmptyFields will wrap empty fields in quotes if true
 	QuoteEmptyFields bool
 
-	// Whether the logger's out is to a terminal
-	isTerminal bool
+	// The NO_COLOR and FORCE_COLOR environment variables, read on init
+	envColors colorChoice
 
 	FieldMap FieldMap
 
@@ -66,15 +108,73 @@ type TextFormatter struct {
 }
 
 func (f *TextFormatter) init(entry *Entry) {
+	f.envColors = envColors()
+
 	// Get the max length of the level text
 	for _, level := range AllLevels {
-		levelTextLength := utf8.RuneCount([]byte(level.String()))
+		levelTextLength := utf8.RuneCountInString(f.levelLabel(level, false))
 		if levelTextLength > f.levelTextMaxLength {
 			f.levelTextMaxLength = levelTextLength
 		}
 	}
 }
 
+// levelLabel returns the text printed for level. Truncation only applies to
+// the default style in colored output.
+func (f *TextFormatter) levelLabel(level Level, truncate bool) string {
+	if label, ok := f.LevelLabels[level]; ok {
+		return label
+	}
+	switch f.LevelLabelStyle {
+	case LevelLabelUpper:
+		return strings.ToUpper(level.String())
+	case LevelLabelShort:
+		if label, ok := shortLevelLabels[level]; ok {
+			return label
+		}
+		return strings.ToUpper(level.String())
+	case LevelLabelLetter:
+		return strings.ToUpper(level.String()[:1])
+	}
+	if !truncate {
+		return level.String()
+	}
+	levelText := strings.ToUpper(level.String())
+	if len(levelText) > 4 {
+		levelText = levelText[0:4]
+	}
+	return levelText
+}
+
+// padLevelText pads levelText to the longest level text when PadLevelText
+// is set.
+func (f *TextFormatter) padLevelText(levelText string) string {
+	if !f.PadLevelText {
+		return levelText
+	}
+	if n := f.levelTextMaxLength - utf8.RuneCountInString(levelText); n > 0 {
+		return levelText + strings.Repeat(" ", n)
+	}
+	return levelText
+}
+
+// isColored reports whether the output gets colors. The options of the
+// formatter come first, then the NO_COLOR and FORCE_COLOR environment
+// variables, then whether the entry is written to a terminal.
+func (f *TextFormatter) isColored(entry *Entry) bool {
+	switch {
+	case f.DisableColors:
+		return false
+	case f.ForceColors:
+		return true
+	case f.envColors == colorNever:
+		return false
+	case f.envColors == colorAlways:
+		return true
+	}
+	return entry.isTerminal()
+}
+
 // Format renders a single log entry
 func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 	//println("txtformat")
@@ -84,6 +184,9 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 		data[k] = v
 	}
 	prefixFieldClashes(data, f.FieldMap, entry.HasCaller())
+	f.terminalInitOnce.Do(func() { f.init(entry) })
+	colored := f.isColored(entry)
+
 	keys := make([]string, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
@@ -123,12 +226,12 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 			sort.Strings(keys)
 			fixedKeys = append(fixedKeys, keys...)
 		} else {
-			//if !f.isColored() {
-			//	fixedKeys = append(fixedKeys, keys...)
-			//	f.SortingFunc(fixedKeys)
-			//} else {
-			f.SortingFunc(keys)
-			//}
+			if !colored {
+				fixedKeys = append(fixedKeys, keys...)
+				f.SortingFunc(fixedKeys)
+			} else {
+				f.SortingFunc(keys)
+			}
 		}
 	} else {
 		fixedKeys = append(fixedKeys, keys...)
@@ -141,41 +244,94 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 		b = &bytes.Buffer{}
 	}
 
-	f.terminalInitOnce.Do(func() { f.init(entry) })
-
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
 		timestampFormat = defaultTimestampFormat
 	}
-	//if f.isColored() {
-	//	f.printColored(b, entry, keys, data, timestampFormat)
-	//} else {
-	for _, key := range fixedKeys {
-		var value interface{}
-		switch {
-		case key == f.FieldMap.resolve(FieldKeyTime):
-			value = entry.Time.Format(timestampFormat)
-		case key == f.FieldMap.resolve(FieldKeyLevel):
-			value = entry.Level.String()
-		case key == f.FieldMap.resolve(FieldKeyMsg):
-			value = entry.Message
-		case key == f.FieldMap.resolve(FieldKeyLogrusError):
-			value = entry.err
-		case key == f.FieldMap.resolve(FieldKeyFunc) && entry.HasCaller():
-			value = funcVal
-		case key == f.FieldMap.resolve(FieldKeyFile) && entry.HasCaller():
-			value = fileVal
-		default:
-			value = data[key]
+	if colored {
+		f.printColored(b, entry, keys, data, timestampFormat)
+	} else {
+		for _, key := range fixedKeys {
+			var value interface{}
+			switch {
+			case key == f.FieldMap.resolve(FieldKeyTime):
+				value = entry.Time.Format(timestampFormat)
+			case key == f.FieldMap.resolve(FieldKeyLevel):
+				levelText := f.levelLabel(entry.Level, false)
+				f.appendKeyValue(b, key, levelText)
+				// pad outside the quotes
+				b.WriteString(f.padLevelText(levelText)[len(levelText):])
+				continue
+			case key == f.FieldMap.resolve(FieldKeyMsg):
+				value = entry.Message
+			case key == f.FieldMap.resolve(FieldKeyLogrusError):
+				value = entry.err
+			case key == f.FieldMap.resolve(FieldKeyFunc) && entry.HasCaller():
+				value = funcVal
+			case key == f.FieldMap.resolve(FieldKeyFile) && entry.HasCaller():
+				value = fileVal
+			default:
+				value = data[key]
+			}
+			f.appendKeyValue(b, key, value)
 		}
-		f.appendKeyValue(b, key, value)
 	}
-	//}
 
 	b.WriteByte('\n')
 	return b.Bytes(), nil
 }
 
+// printColored renders the entry as `LEVL[0012] message  key=value`, with the
+// level text in the color of its level. Keys and values are left uncolored.
+func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, data Fields, timestampFormat string) {
+	levelColor := levelColor(entry.Level)
+
+	levelText := f.levelLabel(entry.Level, !f.DisableLevelTruncation && !f.PadLevelText)
+	if f.LevelLabelStyle == LevelLabelDefault {
+		if _, ok := f.LevelLabels[entry.Level]; !ok {
+			levelText = strings.ToUpper(levelText)
+		}
+	}
+	levelText = f.padLevelText(levelText)
+
+	// Remove a single newline if it already exists in the message to keep
+	// the behavior the same as the stdlib log package
+	message := strings.TrimSuffix(entry.Message, "\n")
+
+	caller := ""
+	if entry.HasCaller() {
+		funcVal := fmt.Sprintf("%s()", entry.Caller.Function)
+		fileVal := fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+
+		if f.CallerPrettyfier != nil {
+			funcVal, fileVal = f.CallerPrettyfier(entry.Caller)
+		}
+
+		if fileVal == "" {
+			caller = funcVal
+		} else if funcVal == "" {
+			caller = fileVal
+		} else {
+			caller = fileVal + " " + funcVal
+		}
+	}
+
+	switch {
+	case f.DisableTimestamp:
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m%s %-44s ", levelColor, levelText, caller, message)
+	case !f.FullTimestamp:
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%04d]%s %-44s ", levelColor, levelText, int(entry.Time.Sub(baseTimestamp)/time.Second), caller, message)
+	default:
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%s]%s %-44s ", levelColor, levelText, entry.Time.Format(timestampFormat), caller, message)
+	}
+	if entry.err != "" {
+		f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyLogrusError), entry.err)
+	}
+	for _, k := range keys {
+		f.appendKeyValue(b, k, data[k])
+	}
+}
+
 func (f *TextFormatter) needsQuoting(text string) bool {
 	if f.ForceQuote {
 		return true
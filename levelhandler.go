@@ -0,0 +1,196 @@
+package logy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelHandler is an http.Handler to inspect and change the levels of the
+// standard logger and of the named loggers at runtime, e.g. on an internal
+// admin port.
+//
+// GET answers with the levels of all loggers, or of the one given by the
+// "logger" query parameter, as JSON. PUT and POST change a level, with a JSON
+// body such as
+//
+//	{"logger": "db.pool", "level": "debug", "ttl": "10m"}
+//
+// The standard logger is named "". When ttl is set, the previous level comes
+// back once it has elapsed, unless the level is changed again through the
+// handler in the meantime.
+type LevelHandler struct {
+	mu     sync.Mutex
+	timers map[string]*levelTimer
+}
+
+// levelTimer restores the level a change with a TTL replaced.
+type levelTimer struct {
+	timer   *time.Timer
+	expires time.Time
+	restore func()
+}
+
+// LevelState describes the level of one logger in LevelHandler responses.
+type LevelState struct {
+	Logger string `json:"logger"`
+	Level  Level  `json:"level"`
+	// Inherited is set when a named logger uses the level of its parent.
+	Inherited bool `json:"inherited,omitempty"`
+	// Expires is when a level set with a TTL reverts.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// levelRequest is the body of PUT and POST requests.
+type levelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	TTL    string `json:"ttl"`
+}
+
+// NewLevelHandler returns a handler for the levels of the standard logger
+// and of the named loggers.
+func NewLevelHandler() *LevelHandler {
+	return &LevelHandler{timers: make(map[string]*levelTimer)}
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if name := r.URL.Query().Get("logger"); name != "" {
+			logger, ok := lookupLogger(name)
+			if !ok {
+				writeLevelError(w, http.StatusNotFound, fmt.Errorf("unknown logger %q", name))
+				return
+			}
+			writeLevelJSON(w, http.StatusOK, h.state(logger))
+			return
+		}
+		states := []LevelState{h.state(std)}
+		for _, name := range NamedLoggers() {
+			states = append(states, h.state(Named(name)))
+		}
+		writeLevelJSON(w, http.StatusOK, states)
+	case http.MethodPut, http.MethodPost:
+		h.serveChange(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (h *LevelHandler) serveChange(w http.ResponseWriter, r *http.Request) {
+	var req levelRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeLevelError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		writeLevelError(w, http.StatusBadRequest, err)
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			writeLevelError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl %q", req.TTL))
+			return
+		}
+	}
+	logger, ok := lookupLogger(req.Logger)
+	if !ok {
+		writeLevelError(w, http.StatusNotFound, fmt.Errorf("unknown logger %q", req.Logger))
+		return
+	}
+
+	h.setLevel(logger, level, ttl)
+	writeLevelJSON(w, http.StatusOK, h.state(logger))
+}
+
+// setLevel sets the level of logger, to be reverted after ttl if not zero.
+func (h *LevelHandler) setLevel(logger *Logger, level Level, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	name := logger.Name()
+	if t, ok := h.timers[name]; ok {
+		// the level to restore is still the one before the pending change
+		t.timer.Stop()
+		delete(h.timers, name)
+		if ttl > 0 {
+			h.schedule(name, t.restore, ttl)
+		}
+	} else if ttl > 0 {
+		h.schedule(name, restoreLevel(logger), ttl)
+	}
+	logger.SetLevel(level)
+}
+
+func (h *LevelHandler) schedule(name string, restore func(), ttl time.Duration) {
+	t := &levelTimer{expires: time.Now().Add(ttl), restore: restore}
+	t.timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.timers[name] != t {
+			return
+		}
+		delete(h.timers, name)
+		restore()
+	})
+	h.timers[name] = t
+}
+
+// restoreLevel returns a function putting back the current level of logger.
+func restoreLevel(logger *Logger) func() {
+	if !logger.HasLevel() {
+		return logger.ResetLevel
+	}
+	level := logger.GetLevel()
+	return func() { logger.SetLevel(level) }
+}
+
+func (h *LevelHandler) state(logger *Logger) LevelState {
+	state := LevelState{
+		Logger:    logger.Name(),
+		Level:     logger.GetLevel(),
+		Inherited: !logger.HasLevel(),
+	}
+	h.mu.Lock()
+	if t, ok := h.timers[logger.Name()]; ok {
+		expires := t.expires
+		state.Expires = &expires
+	}
+	h.mu.Unlock()
+	return state
+}
+
+// lookupLogger returns the standard logger for "" or an existing named
+// logger.
+func lookupLogger(name string) (*Logger, bool) {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return std, true
+	}
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	logger, ok := named[name]
+	return logger, ok
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write level response, %v\n", err)
+	}
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	writeLevelJSON(w, status, map[string]string{"error": err.Error()})
+}
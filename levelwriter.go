@@ -0,0 +1,39 @@
+package logy
+
+import "io"
+
+// LevelWriter is implemented by outputs that route entries by level. When
+// Logger.Out implements it, entries are written with WriteLevel instead of
+// Write.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (n int, err error)
+}
+
+// LevelSplitWriter sends entries to a different writer per level, e.g. to
+// keep an error-only file next to the full log.
+type LevelSplitWriter struct {
+	// Default receives the entries of levels missing from Levels and
+	// everything written with Write.
+	Default io.Writer
+
+	// Levels maps a level to the writer its entries go to instead of
+	// Default. Use io.MultiWriter to send a level to Default as well.
+	Levels map[Level]io.Writer
+}
+
+// Write writes p to Default.
+func (w *LevelSplitWriter) Write(p []byte) (int, error) {
+	if w.Default == nil {
+		return len(p), nil
+	}
+	return w.Default.Write(p)
+}
+
+// WriteLevel writes p to the writer configured for level.
+func (w *LevelSplitWriter) WriteLevel(level Level, p []byte) (int, error) {
+	if out, ok := w.Levels[level]; ok {
+		return out.Write(p)
+	}
+	return w.Write(p)
+}
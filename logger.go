@@ -1,14 +1,10 @@
 package logy
 
 import (
-	"archive/zip"
 	"context"
-	"fmt"
 	"io"
-	"log"
 	"os"
 	"path"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,12 +13,45 @@ import (
 type LogFunction func() []interface{}
 
 type Logger struct {
+	// droppedEntries is first to keep it 64-bit aligned for atomic access.
+	droppedEntries uint64
+
 	maxFileSize int64
 
-	fileObj *os.File
+	rotateInterval time.Duration
+
+	rotateLocation *time.Location
+
+	maxBackups int
+
+	maxAge time.Duration
+
+	maxTotalSize int64
+
+	compressor Compressor
 
-	errFileObj *os.File
+	// onCleanup is passed on to the file writers, see SetOnCleanup.
+	onCleanup func(removed []string, err error)
 
+	// fileObj is the rotating file Out points to while IfwFile is set.
+	fileObj *RotatingFileWriter
+
+	// errFileObj additionally receives Error, Fatal and Panic entries while
+	// IfwFile is set and no level files are configured.
+	errFileObj *RotatingFileWriter
+
+	// levelFiles maps levels to the file their entries go to instead of fn,
+	// levelFileObjs holds one writer per distinct file name.
+	levelFiles map[Level]string
+
+	levelFileObjs map[string]*RotatingFileWriter
+
+	// fileOut is the writer Out is set to while IfwFile is set.
+	fileOut io.Writer
+
+	// IfwFile reports whether entries go to the log files. It is maintained
+	// by Setifwf, which opens and closes the files; assigning it directly
+	// has no effect.
 	IfwFile bool
 
 	fp string
@@ -31,8 +60,36 @@ type Logger struct {
 
 	Out io.Writer
 
+	// Hooks for the logger instance. These allow firing events based on logging
+	// levels and log entries. For example, to send errors to an error tracking
+	// service, log to StatsD or dump the core on fatal errors.
+	Hooks LevelHooks
+
+	// HookErrorHandler is called for every hook that fails to fire. The entry
+	// is still written. When nil the error is printed to stderr.
+	HookErrorHandler func(hook Hook, entry *Entry, err error)
+
 	Formatter Formatter
 
+	// sinks are written to after Out, see AddSink.
+	sinks []Sink
+
+	// async is the queue entries go through in asynchronous mode.
+	async *asyncQueue
+
+	// sampler and levelSamplers thin out repeated entries, see SetSampler.
+	sampler *Sampler
+
+	levelSamplers map[Level]*Sampler
+
+	// dedup suppresses repeated entries, see SetDeduplication.
+	dedup *deduper
+
+	contextExtractors []ContextExtractor
+
+	// signals is the handler started by HandleSignals.
+	signals *signalHandler
+
 	ReportCaller bool
 
 	Level Level
@@ -43,7 +100,25 @@ type Logger struct {
 
 	ExitFunc exitFunc
 
+	// ExitTimeout bounds the time the exit handlers may take in total before
+	// Exit gives up on them. Zero waits for them to return.
+	ExitTimeout time.Duration
+
+	exitHandlers []func()
+
 	BufferPool BufferPool
+
+	// parent and name are set on the loggers created by Named. levelSet
+	// tells whether Level overrides the level of the parent, owned whether
+	// the logger has settings of its own rather than writing through the
+	// parent.
+	parent *Logger
+
+	name string
+
+	levelSet uint32
+
+	owned uint32
 }
 
 type exitFunc func(int)
@@ -74,13 +149,16 @@ func New() *Logger {
 	return &Logger{
 		IfwFile: 	  false,
 		Out:          os.Stderr,
+		Hooks:        make(LevelHooks),
 		Formatter:    new(TextFormatter),
 		Level:        InfoLevel,
 		ExitFunc:     os.Exit,
+		ExitTimeout:  defaultExitTimeout,
 		ReportCaller: false,
 		fp:           "./",
 		fn:           "app.log",
 		maxFileSize:  10240,
+		compressor:   &GzipCompressor{},
 	}
 }
 
@@ -117,53 +195,6 @@ func (logger *Logger) WithError(err error) *Entry {
 	return entry.WithError(err)
 }
 
-func (logger *Logger) zipFile(file *os.File) (*os.File, error) {
-	fileInfo, err := file.Stat()
-	if err != nil {
-		fmt.Printf("get file info failed3,err:%v\n", err)
-		return nil, err
-	}
-	source := fileInfo.Name()
-	backLog := source + ".zip"
-
-	zipFile, err := os.OpenFile(backLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0777)
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	defer zipFile.Close()
-	archive := zip.NewWriter(zipFile)
-	defer archive.Close()
-	return nil, filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			header.Method = zip.Deflate
-		}
-		header.SetModTime(time.Now().UTC())
-		header.Name = path
-		writer, err := archive.CreateHeader(header)
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-		_, err = io.Copy(writer, file)
-		return err
-	})
-}
-
 // Add a context to the log entry.
 func (logger *Logger) WithContext(ctx context.Context) *Entry {
 	entry := logger.newEntry()
@@ -178,44 +209,6 @@ func (logger *Logger) WithTime(t time.Time) *Entry {
 	return entry.WithTime(t)
 }
 
-func (logger *Logger) cutFile(file *os.File) (*os.File, error) {
-	// 获取当前文件名
-	fileInfo, err := file.Stat()
-	if err != nil {
-		fmt.Printf("get file info failed2,err:%v\n", err)
-		return nil, err
-	}
-	append := "_" + time.Now().Format("2006-01-02-15-04-05") + ".log" // 时间戳后缀
-	fileName := fileInfo.Name()
-	newFileName := fileName + append
-	oldPath := path.Join(logger.fp, fileName) // 日志文件的全路径
-	newPath := path.Join(logger.fp, newFileName)
-	fmt.Println(oldPath)
-	fmt.Println(newPath)
-	// 关闭当前文件句柄
-	logger.zipFile(file)
-	file.Close()
-	os.Rename(oldPath, newPath)
-	fileObj, err := os.OpenFile(oldPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Printf("open logFile failed,Err:%v\n", err)
-		return nil, err
-	}
-	return fileObj, nil
-}
-
-func (logger *Logger) checkLogSize(file *os.File) bool { // 利用os.Open()方法打开的文件句柄类型都是 *os.File 这种指针类型
-	fileInfo, err := file.Stat()
-	if err != nil {
-		fmt.Printf("get file info failed1,err:%v\n", err)
-		return false
-		// panic(err)
-	}
-	if fileInfo.Size() > logger.maxFileSize {
-		return true
-	}
-	return false
-}
 // Warning: using Log at Panic or Fatal level will not respectively Panic nor Exit.
 // For this behaviour Logger.Panic or Logger.Fatal should be used instead.
 func (logger *Logger) Log(level Level, args ...interface{}) {
@@ -267,46 +260,477 @@ func (logger *Logger) Panic(args ...interface{}) {
 	logger.Log(PanicLevel, args...)
 }
 
+// FatalWithCode logs a message at level Fatal then exits with the given
+// status code.
+func (logger *Logger) FatalWithCode(code int, args ...interface{}) {
+	logger.Log(FatalLevel, args...)
+	logger.Exit(code)
+}
+
+func (logger *Logger) Print(args ...interface{}) {
+	entry := logger.newEntry()
+	entry.Print(args...)
+	logger.releaseEntry(entry)
+}
+
+// Logf logs a formatted message at the level given as parameter. The format
+// string is only expanded when the level is enabled.
+func (logger *Logger) Logf(level Level, format string, args ...interface{}) {
+	if logger.IsLevelEnabled(level) {
+		entry := logger.newEntry()
+		entry.Logf(level, format, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) Tracef(format string, args ...interface{}) {
+	logger.Logf(TraceLevel, format, args...)
+}
+
+func (logger *Logger) Debugf(format string, args ...interface{}) {
+	logger.Logf(DebugLevel, format, args...)
+}
+
+func (logger *Logger) Infof(format string, args ...interface{}) {
+	logger.Logf(InfoLevel, format, args...)
+}
+
+func (logger *Logger) Printf(format string, args ...interface{}) {
+	entry := logger.newEntry()
+	entry.Printf(format, args...)
+	logger.releaseEntry(entry)
+}
+
+func (logger *Logger) Warnf(format string, args ...interface{}) {
+	logger.Logf(WarnLevel, format, args...)
+}
+
+func (logger *Logger) Warningf(format string, args ...interface{}) {
+	logger.Warnf(format, args...)
+}
+
+func (logger *Logger) Errorf(format string, args ...interface{}) {
+	logger.Logf(ErrorLevel, format, args...)
+}
+
+func (logger *Logger) Fatalf(format string, args ...interface{}) {
+	logger.Logf(FatalLevel, format, args...)
+	logger.Exit(1)
+}
+
+func (logger *Logger) Panicf(format string, args ...interface{}) {
+	logger.Logf(PanicLevel, format, args...)
+}
+
+// Logln logs a message at the level given as parameter, with operands
+// separated by spaces as in fmt.Println.
+func (logger *Logger) Logln(level Level, args ...interface{}) {
+	if logger.IsLevelEnabled(level) {
+		entry := logger.newEntry()
+		entry.Logln(level, args...)
+		logger.releaseEntry(entry)
+	}
+}
+
+func (logger *Logger) Traceln(args ...interface{}) {
+	logger.Logln(TraceLevel, args...)
+}
+
+func (logger *Logger) Debugln(args ...interface{}) {
+	logger.Logln(DebugLevel, args...)
+}
+
+func (logger *Logger) Infoln(args ...interface{}) {
+	logger.Logln(InfoLevel, args...)
+}
+
+func (logger *Logger) Println(args ...interface{}) {
+	entry := logger.newEntry()
+	entry.Println(args...)
+	logger.releaseEntry(entry)
+}
+
+func (logger *Logger) Warnln(args ...interface{}) {
+	logger.Logln(WarnLevel, args...)
+}
+
+func (logger *Logger) Warningln(args ...interface{}) {
+	logger.Warnln(args...)
+}
+
+func (logger *Logger) Errorln(args ...interface{}) {
+	logger.Logln(ErrorLevel, args...)
+}
+
+func (logger *Logger) Fatalln(args ...interface{}) {
+	logger.Logln(FatalLevel, args...)
+	logger.Exit(1)
+}
+
+func (logger *Logger) Panicln(args ...interface{}) {
+	logger.Logln(PanicLevel, args...)
+}
+
 
+// Exit syncs the outputs of the logger, runs its exit handlers followed by
+// the ones registered on the package, and terminates the program using
+// ExitFunc(code). The handlers run in isolation from each other's panics
+// and are abandoned after ExitTimeout.
 func (logger *Logger) Exit(code int) {
-	//runHandlers()   //退出
+	if out := logger.output(); out != logger {
+		out.Exit(code)
+		return
+	}
+	logger.Sync()
+
+	logger.mu.Lock()
+	exitHandlers := append(append([]func(){}, logger.exitHandlers...), globalHandlers()...)
+	timeout := logger.ExitTimeout
+	logger.mu.Unlock()
+	runHandlers(exitHandlers, timeout)
+	logger.Sync()
+
 	if logger.ExitFunc == nil {
 		logger.ExitFunc = os.Exit
 	}
 	logger.ExitFunc(code)
 }
 
+// Sync writes out everything the logger holds: it logs pending
+// deduplication summaries, waits for the asynchronous queue to drain,
+// flushes the sinks and commits the log files
+// to stable storage. It returns the first error met. Sync is called before
+// Fatal exits and before Panic panics.
+func (logger *Logger) Sync() error {
+	if out := logger.output(); out != logger {
+		return out.Sync()
+	}
+	logger.mu.Lock()
+	dedup := logger.dedup
+	logger.mu.Unlock()
+	if dedup != nil {
+		dedup.flush()
+	}
+	logger.Flush()
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	var first error
+	keep := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	for _, sink := range logger.sinks {
+		keep(sink.Flush())
+	}
+	for _, w := range logger.fileWriters() {
+		keep(w.Sync())
+	}
+	if logger.Out != logger.fileOut && logger.Out != io.Writer(os.Stdout) && logger.Out != io.Writer(os.Stderr) {
+		switch out := logger.Out.(type) {
+		case interface{ Flush() error }:
+			keep(out.Flush())
+		case interface{ Sync() error }:
+			keep(out.Sync())
+		}
+	}
+	return first
+}
+
+// reopener is implemented by outputs that can reopen their files, such as
+// RotatingFileWriter.
+type reopener interface {
+	Reopen() error
+}
+
+// Reopen reopens the log files of the logger, those opened for Setifwf as
+// well as an Out or sinks supporting it, so that entries go to fresh files
+// after they were moved away. It returns the first error met.
+func (logger *Logger) Reopen() error {
+	if out := logger.output(); out != logger {
+		return out.Reopen()
+	}
+	logger.Flush()
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	var first error
+	keep := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	for _, w := range logger.fileWriters() {
+		keep(w.Reopen())
+	}
+	if r, ok := logger.Out.(reopener); ok && logger.Out != logger.fileOut {
+		keep(r.Reopen())
+	}
+	for _, sink := range logger.sinks {
+		if r, ok := sink.(reopener); ok {
+			keep(r.Reopen())
+		}
+	}
+	return first
+}
+
+// Close syncs the logger, then closes every output it owns: the files
+// opened for Setifwf and the sinks. An Out set with SetOutput is synced but
+// left open. Afterwards entries go to stderr. A signal handler started with
+// HandleSignals is stopped first.
+func (logger *Logger) Close() error {
+	logger.StopSignals()
+	if logger.output() != logger {
+		// a named logger without outputs of its own
+		return nil
+	}
+	logger.SetDeduplication(0)
+	logger.DisableAsync()
+	first := logger.Sync()
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	keep := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	for _, sink := range logger.sinks {
+		keep(sink.Close())
+	}
+	logger.sinks = nil
+	for _, w := range logger.fileWriters() {
+		keep(w.Close())
+	}
+	logger.fileObj = nil
+	logger.errFileObj = nil
+	logger.levelFileObjs = nil
+	if logger.fileOut != nil && logger.Out == logger.fileOut {
+		logger.Out = os.Stderr
+	}
+	logger.fileOut = nil
+	logger.IfwFile = false
+	return first
+}
+
+// Setifwf switches file output on or off. When on, Out is replaced by
+// RotatingFileWriters for the file configured with SetFilepn, rotated after
+// SetmaxFileSize bytes. Error, Fatal and Panic entries are also written to
+// `error-<fn>` next to it, unless SetLevelFiles routes levels to files of
+// their own. When switched off the files are closed and Out goes back to
+// stderr.
 func (logger *Logger) Setifwf(flag bool) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.IfwFile = flag
+	if flag {
+		logger.openFileOutput()
+		return
+	}
+	if logger.fileOut != nil && logger.Out == logger.fileOut {
+		logger.Out = os.Stderr
+	}
+	logger.fileOut = nil
+	logger.closeFiles()
+}
+
+// SetLevelFiles sends the entries of each level in files to a file of its
+// own, e.g. {InfoLevel: "info.log", WarnLevel: "warn.log", ErrorLevel:
+// "error.log"}. Names are relative to the directory set with SetFilepn and
+// several levels may share a file. Levels missing from files still go to the
+// main log file. Every file is rotated and cleaned up on its own with the
+// settings of the logger. A nil map restores the default error file.
+func (logger *Logger) SetLevelFiles(files map[Level]string) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.levelFiles = make(map[Level]string, len(files))
+	for level, fn := range files {
+		logger.levelFiles[level] = fn
+	}
+	if logger.IfwFile {
+		logger.closeFiles()
+		logger.openFileOutput()
+	}
+}
+
+// openFileOutput points Out at the log files, creating their writers as
+// needed.
+func (logger *Logger) openFileOutput() {
+	if logger.fileObj == nil {
+		logger.fileObj = logger.newFileWriter(path.Join(logger.fp, logger.fn))
+	}
+	split := &LevelSplitWriter{Default: logger.fileObj, Levels: make(map[Level]io.Writer)}
+	if len(logger.levelFiles) == 0 {
+		if logger.errFileObj == nil {
+			logger.errFileObj = logger.newFileWriter(path.Join(logger.fp, "error-"+logger.fn))
+		}
+		errOut := io.MultiWriter(logger.fileObj, logger.errFileObj)
+		for _, level := range []Level{PanicLevel, FatalLevel, ErrorLevel} {
+			split.Levels[level] = errOut
+		}
+	} else {
+		if logger.levelFileObjs == nil {
+			logger.levelFileObjs = make(map[string]*RotatingFileWriter)
+		}
+		for level, fn := range logger.levelFiles {
+			w, ok := logger.levelFileObjs[fn]
+			if !ok {
+				w = logger.newFileWriter(path.Join(logger.fp, fn))
+				logger.levelFileObjs[fn] = w
+			}
+			split.Levels[level] = w
+		}
+	}
+	logger.fileOut = split
+	logger.Out = split
 }
 
+// fileWriters returns every file writer owned by the logger.
+func (logger *Logger) fileWriters() []*RotatingFileWriter {
+	var writers []*RotatingFileWriter
+	if logger.fileObj != nil {
+		writers = append(writers, logger.fileObj)
+	}
+	if logger.errFileObj != nil {
+		writers = append(writers, logger.errFileObj)
+	}
+	for _, w := range logger.levelFileObjs {
+		writers = append(writers, w)
+	}
+	return writers
+}
 
+// closeFiles closes and forgets every file writer owned by the logger.
+func (logger *Logger) closeFiles() {
+	for _, w := range logger.fileWriters() {
+		w.Close()
+	}
+	logger.fileObj = nil
+	logger.errFileObj = nil
+	logger.levelFileObjs = nil
+}
+
+// newFileWriter returns a RotatingFileWriter for filename using the rotation
+// settings of the logger.
+func (logger *Logger) newFileWriter(filename string) *RotatingFileWriter {
+	w := NewRotatingFileWriter(filename, logger.maxFileSize)
+	w.RotateInterval = logger.rotateInterval
+	w.Location = logger.rotateLocation
+	w.MaxBackups = logger.maxBackups
+	w.MaxAge = logger.maxAge
+	w.MaxTotalSize = logger.maxTotalSize
+	w.Compress = logger.compressor != nil
+	w.Compressor = logger.compressor
+	w.OnCleanup = logger.onCleanup
+	return w
+}
+
+// SetFilepn sets the name and directory of the log file used when IfwFile is
+// set. Both may contain the strftime-style verbs understood by
+// RotatingFileWriter. Open files are closed and the new ones are used from
+// the next entry.
 func (logger *Logger) SetFilepn(fn, fp string) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.fp = fp
 	logger.fn = fn
+	if logger.IfwFile {
+		logger.closeFiles()
+		logger.openFileOutput()
+	}
 }
 
+// SetmaxFileSize sets the size in bytes after which the log files are
+// rotated.
 func (logger *Logger) SetmaxFileSize(size int64) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.maxFileSize = size
+	for _, w := range logger.fileWriters() {
+		w.SetMaxSize(size)
+	}
+}
+
+// SetRotateInterval rotates the log files every interval, aligned to
+// wall-clock boundaries in loc (time.Local when nil). Zero disables time
+// based rotation.
+func (logger *Logger) SetRotateInterval(interval time.Duration, loc *time.Location) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.rotateInterval = interval
+	logger.rotateLocation = loc
+	for _, w := range logger.fileWriters() {
+		w.SetRotateInterval(interval, loc)
+	}
+}
+
+// SetRetention sets how many rotated log files are kept, how old they may get
+// and how much space the log files may take in total. Zero values disable the
+// respective limit. The limits apply to each log file separately. Old files
+// are removed in the background, see SetOnCleanup to be told about them.
+func (logger *Logger) SetRetention(maxBackups int, maxAge time.Duration, maxTotalSize int64) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.maxBackups = maxBackups
+	logger.maxAge = maxAge
+	logger.maxTotalSize = maxTotalSize
+	for _, w := range logger.fileWriters() {
+		w.SetRetention(maxBackups, maxAge, maxTotalSize)
+	}
+}
+
+// SetCompressor sets the codec rotated log files are compressed with. They are
+// gzipped by default, nil disables compression.
+func (logger *Logger) SetCompressor(compressor Compressor) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.compressor = compressor
+	for _, w := range logger.fileWriters() {
+		w.SetCompressor(compressor)
+	}
+}
+
+// SetOnCleanup sets the function told about the log files removed by the
+// retention policy, see RotatingFileWriter.OnCleanup. It is called from the
+// background goroutine of each log file, possibly concurrently. When nil,
+// only failures are reported, on stderr.
+func (logger *Logger) SetOnCleanup(fn func(removed []string, err error)) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.onCleanup = fn
+	for _, w := range logger.fileWriters() {
+		w.SetOnCleanup(fn)
+	}
 }
 
 func (logger *Logger) SetNoLock() {
+	logger.own()
 	logger.mu.Disable()
 }
 
 func (logger *Logger) level() Level {
+	for logger.parent != nil && atomic.LoadUint32(&logger.levelSet) == 0 {
+		logger = logger.parent
+	}
 	return Level(atomic.LoadUint32((*uint32)(&logger.Level)))
 }
 
-// SetLevel sets the logger level.
+// SetLevel sets the logger level. On a named logger it overrides the level
+// inherited from the parent, see ResetLevel.
 func (logger *Logger) SetLevel(level Level) {
 	atomic.StoreUint32((*uint32)(&logger.Level), uint32(level))
+	if logger.parent != nil {
+		atomic.StoreUint32(&logger.levelSet, 1)
+	}
 }
 
 // GetLevel returns the logger level.
@@ -319,8 +743,30 @@ func (logger *Logger) IsLevelEnabled(level Level) bool {
 	return logger.level() >= level
 }
 
+// AddHook adds a hook to the logger hooks.
+func (logger *Logger) AddHook(hook Hook) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.Hooks == nil {
+		logger.Hooks = make(LevelHooks)
+	}
+	logger.Hooks.Add(hook)
+}
+
+// ReplaceHooks replaces the logger hooks and returns the old ones
+func (logger *Logger) ReplaceHooks(hooks LevelHooks) LevelHooks {
+	logger.own()
+	logger.mu.Lock()
+	oldHooks := logger.Hooks
+	logger.Hooks = hooks
+	logger.mu.Unlock()
+	return oldHooks
+}
+
 // SetFormatter sets the logger formatter.
 func (logger *Logger) SetFormatter(formatter Formatter) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.Formatter = formatter
@@ -328,12 +774,14 @@ func (logger *Logger) SetFormatter(formatter Formatter) {
 
 // SetOutput sets the logger output.
 func (logger *Logger) SetOutput(output io.Writer) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.Out = output
 }
 
 func (logger *Logger) SetReportCaller(reportCaller bool) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.ReportCaller = reportCaller
@@ -341,6 +789,7 @@ func (logger *Logger) SetReportCaller(reportCaller bool) {
 
 //SetBufferPool sets the logger buffer pool.
 func (logger *Logger) SetBufferPool(pool BufferPool) {
+	logger.own()
 	logger.mu.Lock()
 	defer logger.mu.Unlock()
 	logger.BufferPool = pool
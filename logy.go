@@ -1,8 +1,9 @@
 package logy
 
 import (
+	"flag"
 	"fmt"
-	//"log"
+	"log"
 	"strings"
 )
 
@@ -56,6 +57,13 @@ func (level *Level) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Set implements flag.Value.
+func (level *Level) Set(s string) error {
+	return level.UnmarshalText([]byte(s))
+}
+
+var _ flag.Value = new(Level)
+
 func (level Level) MarshalText() ([]byte, error) {
 	switch level {
 	case TraceLevel:
@@ -107,14 +115,16 @@ const (
 	TraceLevel
 )
 
-/*
-
+// Won't compile if StdLogger can't be realized by a log.Logger
 var (
-	
-	
-	//_ StdLogger = &Logger{}
+	_ StdLogger = &log.Logger{}
+	_ StdLogger = &Entry{}
+	_ StdLogger = &Logger{}
 )
 
+// StdLogger is what your logy-enabled library should take, that way
+// it'll accept a stdlib logger and a logy logger. There's no standard
+// interface, this is the closest we get, unfortunately.
 type StdLogger interface {
 	Print(...interface{})
 	Printf(string, ...interface{})
@@ -131,6 +141,19 @@ type StdLogger interface {
 
 // The FieldLogger interface generalizes the Entry and Logger types
 type FieldLogger interface {
+	WithField(key string, value interface{}) *Entry
+	WithFields(fields Fields) *Entry
+	WithError(err error) *Entry
+
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Printf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Panicf(format string, args ...interface{})
+
 	Debug(args ...interface{})
 	Info(args ...interface{})
 	Print(args ...interface{})
@@ -148,12 +171,19 @@ type FieldLogger interface {
 	Errorln(args ...interface{})
 	Fatalln(args ...interface{})
 	Panicln(args ...interface{})
-
 }
+
+// Ext1FieldLogger extends FieldLogger with the Trace family of methods.
 type Ext1FieldLogger interface {
 	FieldLogger
 	Tracef(format string, args ...interface{})
 	Trace(args ...interface{})
 	Traceln(args ...interface{})
 }
-*/
+
+var (
+	_ FieldLogger     = &Entry{}
+	_ FieldLogger     = &Logger{}
+	_ Ext1FieldLogger = &Entry{}
+	_ Ext1FieldLogger = &Logger{}
+)
@@ -0,0 +1,140 @@
+package logy
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerKey is the field holding the name of the named logger an entry was
+// logged with.
+const LoggerKey = "logger"
+
+var (
+	namedMu sync.Mutex
+	named   = make(map[string]*Logger)
+)
+
+// Named returns the logger registered under name, creating it and its
+// ancestors as needed. Names are dot separated paths: "db.pool" is a child of
+// "db", whose parent is the standard logger. A named logger takes its level
+// from the closest ancestor with a level set until SetLevel is called on it,
+// so a level change applies to every descendant without an override of its
+// own. Entries carry the name in the LoggerKey field.
+//
+// Other settings are inherited the same way: entries are written by the
+// closest ancestor with settings of its own, using its outputs, formatter and
+// hooks. Calling a setter such as SetOutput, SetFormatter or AddHook on a
+// named logger gives it settings of its own, starting from a copy of the
+// inherited ones, which then apply to its descendants. Assigning the exported
+// fields of a named logger directly has no effect before that.
+func Named(name string) *Logger {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return std
+	}
+
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	return namedLocked(name)
+}
+
+func namedLocked(name string) *Logger {
+	if logger, ok := named[name]; ok {
+		return logger
+	}
+	parent := std
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		parent = namedLocked(name[:i])
+	}
+	logger := New()
+	logger.parent = parent
+	logger.name = name
+	named[name] = logger
+	return logger
+}
+
+// NamedLoggers returns the names of the loggers created by Named, sorted.
+func NamedLoggers() []string {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the name of a logger created by Named, or "" for others.
+func (logger *Logger) Name() string {
+	return logger.name
+}
+
+// ResetLevel drops the level set on a named logger, which inherits the level
+// of its parent again. It has no effect on other loggers.
+func (logger *Logger) ResetLevel() {
+	if logger.parent != nil {
+		atomic.StoreUint32(&logger.levelSet, 0)
+	}
+}
+
+// HasLevel reports whether the level of the logger is its own rather than
+// inherited from a parent.
+func (logger *Logger) HasLevel() bool {
+	return logger.parent == nil || atomic.LoadUint32(&logger.levelSet) != 0
+}
+
+// output returns the logger the entries of logger are written by: itself,
+// or for a named logger without settings of its own, the closest ancestor
+// having some.
+func (logger *Logger) output() *Logger {
+	for logger.parent != nil && atomic.LoadUint32(&logger.owned) == 0 {
+		logger = logger.parent
+	}
+	return logger
+}
+
+// own gives a named logger settings of its own, before one of them is
+// changed. They start as a copy of the inherited formatter, output, hooks,
+// caller reporting, context extractors, buffer pool and exit settings. Sinks,
+// file output, asynchronous mode, sampling and deduplication belong to the
+// logger that set them up and start off for the named logger. It does nothing
+// for other loggers.
+func (logger *Logger) own() {
+	if logger.parent == nil || atomic.LoadUint32(&logger.owned) != 0 {
+		return
+	}
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	if atomic.LoadUint32(&logger.owned) != 0 {
+		return
+	}
+
+	src := logger.parent.output()
+	src.mu.Lock()
+	out, formatter, reportCaller := src.Out, src.Formatter, src.ReportCaller
+	hooks := make(LevelHooks, len(src.Hooks))
+	for level, levelHooks := range src.Hooks {
+		hooks[level] = append([]Hook(nil), levelHooks...)
+	}
+	hookErrorHandler := src.HookErrorHandler
+	extractors := append([]ContextExtractor(nil), src.contextExtractors...)
+	bufferPool := src.BufferPool
+	exitFunc, exitTimeout := src.ExitFunc, src.ExitTimeout
+	src.mu.Unlock()
+
+	logger.mu.Lock()
+	logger.Out = out
+	logger.Formatter = formatter
+	logger.ReportCaller = reportCaller
+	logger.Hooks = hooks
+	logger.HookErrorHandler = hookErrorHandler
+	logger.contextExtractors = extractors
+	logger.BufferPool = bufferPool
+	logger.ExitFunc = exitFunc
+	logger.ExitTimeout = exitTimeout
+	logger.mu.Unlock()
+	atomic.StoreUint32(&logger.owned, 1)
+}
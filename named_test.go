@@ -0,0 +1,52 @@
+package logy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// namedRuns makes the names of named loggers unique to a test run: they
+// stay in the registry, settings included, for the life of the process.
+var namedRuns int
+
+func uniqueName(t *testing.T) string {
+	namedRuns++
+	return fmt.Sprintf("%s%d", t.Name(), namedRuns)
+}
+
+func TestNamedOwnSettings(t *testing.T) {
+	var root, own bytes.Buffer
+	oldOut, oldFormatter := std.Out, std.Formatter
+	defer func() {
+		std.SetOutput(oldOut)
+		std.SetFormatter(oldFormatter)
+	}()
+	std.SetOutput(&root)
+	std.SetFormatter(&TextFormatter{DisableTimestamp: true})
+
+	name := uniqueName(t)
+	parent := Named(name + ".named")
+	child := Named(name + ".named.child")
+	sibling := Named(name + ".other")
+
+	child.Info("inherited")
+	parent.SetOutput(&own)
+	child.Info("child")
+	parent.Info("parent")
+	sibling.Info("sibling")
+	std.Info("std")
+
+	if got, want := own.String(), "level=info msg=child logger="+name+".named.child\nlevel=info msg=parent logger="+name+".named\n"; got != want {
+		t.Errorf("named output = %q, want %q", got, want)
+	}
+	for _, msg := range []string{"msg=inherited", "msg=sibling", "msg=std"} {
+		if !strings.Contains(root.String(), msg) {
+			t.Errorf("%s missing from %q", msg, root.String())
+		}
+	}
+	if strings.Contains(root.String(), "msg=child") {
+		t.Errorf("child entry written to the standard logger: %q", root.String())
+	}
+}
@@ -0,0 +1,298 @@
+// Package reader parses the output of the logy TextFormatter and
+// JSONFormatter back into logy.Entry values.
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tortoise-daddy/logy"
+)
+
+// Format is the layout of the lines to parse.
+type Format int
+
+const (
+	// Auto parses lines starting with '{' as JSON and the rest as text.
+	Auto Format = iota
+	// Text parses key=value lines written by logy.TextFormatter.
+	Text
+	// JSON parses lines written by logy.JSONFormatter.
+	JSON
+)
+
+// Options describes how the lines were written. They mirror the options of
+// the formatter that produced them.
+type Options struct {
+	Format Format
+
+	// FieldMap renames the default keys, as in the formatter.
+	FieldMap logy.FieldMap
+
+	// DataKey is the key the fields are nested under in JSON, if any.
+	DataKey string
+
+	// TimestampFormat is the layout of the time, time.RFC3339 by default.
+	// RFC 3339 times are accepted whatever the layout.
+	TimestampFormat string
+}
+
+// ErrSyntax is wrapped by the errors returned for malformed lines.
+var ErrSyntax = errors.New("malformed log line")
+
+// Reader reads entries line by line.
+type Reader struct {
+	r    *bufio.Reader
+	opts Options
+	line int
+}
+
+// NewReader returns a Reader parsing the lines of r.
+func NewReader(r io.Reader, opts Options) *Reader {
+	return &Reader{r: bufio.NewReader(r), opts: opts}
+}
+
+// Next returns the entry on the next non-empty line. It returns io.EOF when
+// the input is exhausted. A malformed line yields an error wrapping
+// ErrSyntax; reading may go on with the following line.
+func (r *Reader) Next() (*logy.Entry, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+		r.line++
+		line = bytes.TrimRight(line, "\r\n")
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		entry, perr := Parse(line, r.opts)
+		if perr != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, perr)
+		}
+		return entry, nil
+	}
+}
+
+// Parse parses a single line, with or without its line ending. Lines
+// without a level are rejected.
+func Parse(line []byte, opts Options) (*logy.Entry, error) {
+	line = bytes.TrimRight(line, "\r\n")
+	format := opts.Format
+	if format == Auto {
+		format = Text
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = JSON
+		}
+	}
+	var data, nested logy.Fields
+	var err error
+	if format == JSON {
+		data, nested, err = parseJSON(line, opts)
+	} else {
+		data, err = parseText(string(line))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buildEntry(data, nested, opts)
+}
+
+// parseJSON decodes a JSON line. With DataKey set, the object nested under
+// it is returned apart from the top-level keys.
+func parseJSON(line []byte, opts Options) (data, nested logy.Fields, err error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+	}
+	if opts.DataKey != "" {
+		if fields, ok := data[opts.DataKey].(map[string]interface{}); ok {
+			delete(data, opts.DataKey)
+			nested = logy.Fields(fields)
+		}
+	}
+	return data, nested, nil
+}
+
+// parseText splits a line of key=value pairs. Values are either bare words
+// or Go quoted strings, as written by TextFormatter.
+func parseText(line string) (logy.Fields, error) {
+	data := make(logy.Fields)
+	for {
+		line = strings.TrimLeft(line, " ")
+		if line == "" {
+			return data, nil
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: expected key=value at %q", ErrSyntax, line)
+		}
+		key := line[:eq]
+		if strings.ContainsAny(key, " \"") {
+			return nil, fmt.Errorf("%w: bad key %q", ErrSyntax, key)
+		}
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := quotedEnd(line)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated value for %q", ErrSyntax, key)
+			}
+			v, err := strconv.Unquote(line[:end])
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad value for %q: %v", ErrSyntax, key, err)
+			}
+			value, line = v, line[end:]
+		} else if sp := strings.IndexByte(line, ' '); sp >= 0 {
+			value, line = line[:sp], line[sp:]
+		} else {
+			value, line = line, ""
+		}
+		data[key] = value
+	}
+}
+
+// quotedEnd returns the index just past the closing quote of the quoted
+// string s starts with, or -1.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// buildEntry moves the default keys of data into the entry fields and
+// undoes the renaming of clashing fields. Fields nested under the DataKey,
+// if any, become the entry data as they are: they can't clash with the
+// default keys, which are only read from data.
+func buildEntry(data, nested logy.Fields, opts Options) (*logy.Entry, error) {
+	entry := &logy.Entry{Data: nested}
+	if nested == nil {
+		entry.Data = make(logy.Fields, len(data))
+	}
+	// set adds a top-level key to the entry data, without replacing a
+	// nested field of the same name
+	set := func(key string, v interface{}) {
+		if _, ok := entry.Data[key]; ok && nested != nil {
+			return
+		}
+		entry.Data[key] = v
+	}
+	resolve := func(key string) string {
+		for k, v := range opts.FieldMap {
+			if string(k) == key {
+				return v
+			}
+		}
+		return key
+	}
+	timeKey := resolve(logy.FieldKeyTime)
+	levelKey := resolve(logy.FieldKeyLevel)
+	msgKey := resolve(logy.FieldKeyMsg)
+	funcKey := resolve(logy.FieldKeyFunc)
+	fileKey := resolve(logy.FieldKeyFile)
+	errKey := resolve(logy.FieldKeyLogrusError)
+
+	if _, ok := data[levelKey]; !ok {
+		return nil, fmt.Errorf("%w: no %q key", ErrSyntax, levelKey)
+	}
+
+	var funcVal, fileVal string
+	for k, v := range data {
+		switch k {
+		case timeKey:
+			t, err := parseTime(fmt.Sprint(v), opts.TimestampFormat)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+			}
+			entry.Time = t
+		case levelKey:
+			level, err := ParseLevelLabel(fmt.Sprint(v))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSyntax, err)
+			}
+			entry.Level = level
+		case msgKey:
+			entry.Message = fmt.Sprint(v)
+		case funcKey:
+			funcVal = fmt.Sprint(v)
+		case fileKey:
+			fileVal = fmt.Sprint(v)
+		case errKey:
+			set(logy.FieldKeyLogrusError, v)
+		default:
+			set(k, v)
+		}
+	}
+	if nested == nil {
+		for _, key := range []string{timeKey, levelKey, msgKey, funcKey, fileKey, errKey} {
+			if v, ok := entry.Data["fields."+key]; ok {
+				delete(entry.Data, "fields."+key)
+				entry.Data[key] = v
+			}
+		}
+	}
+
+	if funcVal != "" || fileVal != "" {
+		frame := &runtime.Frame{Function: funcVal, File: fileVal}
+		if i := strings.LastIndexByte(fileVal, ':'); i >= 0 {
+			if line, err := strconv.Atoi(fileVal[i+1:]); err == nil {
+				frame.File, frame.Line = fileVal[:i], line
+			}
+		}
+		entry.Caller = frame
+	}
+	return entry, nil
+}
+
+func parseTime(s, layout string) (time.Time, error) {
+	if layout != "" {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// ParseLevelLabel parses a level name as written by any of the level label
+// styles of TextFormatter: "warning", "WARN", "WRN" or "W".
+func ParseLevelLabel(label string) (logy.Level, error) {
+	if level, err := logy.ParseLevel(label); err == nil {
+		return level, nil
+	}
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "PANI", "PNC", "P":
+		return logy.PanicLevel, nil
+	case "FATA", "FTL", "F":
+		return logy.FatalLevel, nil
+	case "ERRO", "ERR", "E":
+		return logy.ErrorLevel, nil
+	case "WRN", "W":
+		return logy.WarnLevel, nil
+	case "INF", "I":
+		return logy.InfoLevel, nil
+	case "DEBU", "DBG", "D":
+		return logy.DebugLevel, nil
+	case "TRAC", "TRC", "T":
+		return logy.TraceLevel, nil
+	}
+	return logy.ParseLevel(label)
+}
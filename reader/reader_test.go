@@ -0,0 +1,243 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tortoise-daddy/logy"
+)
+
+// format renders an entry the way a logger would.
+func format(t *testing.T, f logy.Formatter, level logy.Level, msg string, fields logy.Fields, caller *runtime.Frame) []byte {
+	t.Helper()
+	logger := logy.New()
+	logger.ReportCaller = caller != nil
+	entry := &logy.Entry{
+		Logger:  logger,
+		Data:    fields,
+		Time:    time.Date(2024, 3, 9, 14, 30, 5, 0, time.UTC),
+		Level:   level,
+		Message: msg,
+		Caller:  caller,
+	}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// checkEntry compares a parsed entry with what was logged, comparing field
+// values by their printed form.
+func checkEntry(t *testing.T, got *logy.Entry, level logy.Level, msg string, fields logy.Fields) {
+	t.Helper()
+	if got.Level != level || got.Message != msg {
+		t.Errorf("got %v %q, want %v %q", got.Level, got.Message, level, msg)
+	}
+	if !got.Time.Equal(time.Date(2024, 3, 9, 14, 30, 5, 0, time.UTC)) {
+		t.Errorf("time = %v", got.Time)
+	}
+	if len(got.Data) != len(fields) {
+		t.Errorf("fields = %v, want %v", got.Data, fields)
+	}
+	for k, v := range fields {
+		if fmt.Sprint(got.Data[k]) != fmt.Sprint(v) {
+			t.Errorf("field %s = %v, want %v", k, got.Data[k], v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	fields := logy.Fields{
+		"user":  "alice",
+		"path":  "/a b/c",
+		"quote": `say "hi"`,
+		"empty": "",
+		"count": 42,
+	}
+	tests := []struct {
+		name      string
+		formatter logy.Formatter
+		opts      Options
+	}{
+		{"text", &logy.TextFormatter{DisableColors: true}, Options{Format: Text}},
+		{"text quoted", &logy.TextFormatter{DisableColors: true, ForceQuote: true}, Options{}},
+		{"json", &logy.JSONFormatter{}, Options{Format: JSON}},
+		{"json auto", &logy.JSONFormatter{}, Options{}},
+		{"json data key", &logy.JSONFormatter{DataKey: "data"}, Options{DataKey: "data"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := format(t, tt.formatter, logy.WarnLevel, "disk almost full", fields, nil)
+			entry, err := Parse(line, tt.opts)
+			if err != nil {
+				t.Fatalf("%v in %s", err, line)
+			}
+			checkEntry(t, entry, logy.WarnLevel, "disk almost full", fields)
+		})
+	}
+}
+
+func TestRoundTripFieldMap(t *testing.T) {
+	fieldMap := logy.FieldMap{
+		logy.FieldKeyTime:  "@timestamp",
+		logy.FieldKeyLevel: "severity",
+		logy.FieldKeyMsg:   "message",
+	}
+	fields := logy.Fields{"user": "alice"}
+	for _, f := range []logy.Formatter{
+		&logy.TextFormatter{DisableColors: true, FieldMap: fieldMap},
+		&logy.JSONFormatter{FieldMap: fieldMap},
+	} {
+		line := format(t, f, logy.ErrorLevel, "failed", fields, nil)
+		entry, err := Parse(line, Options{FieldMap: fieldMap})
+		if err != nil {
+			t.Fatalf("%v in %s", err, line)
+		}
+		checkEntry(t, entry, logy.ErrorLevel, "failed", fields)
+
+		if _, err := Parse(line, Options{}); !errors.Is(err, ErrSyntax) {
+			t.Errorf("parsed %s without the FieldMap, err = %v", line, err)
+		}
+	}
+}
+
+func TestRoundTripClashes(t *testing.T) {
+	fields := logy.Fields{
+		"time":  "user-time",
+		"level": "user-level",
+		"msg":   "user-msg",
+		"func":  "user-func",
+		"file":  "user-file",
+	}
+	caller := &runtime.Frame{Function: "main.run", File: "/src/app/main.go", Line: 42}
+	for _, f := range []logy.Formatter{
+		&logy.TextFormatter{DisableColors: true},
+		&logy.JSONFormatter{},
+		&logy.JSONFormatter{DataKey: "data"},
+	} {
+		line := format(t, f, logy.ErrorLevel, "real message", fields, caller)
+		entry, err := Parse(line, Options{DataKey: "data"})
+		if err != nil {
+			t.Fatalf("%v in %s", err, line)
+		}
+		checkEntry(t, entry, logy.ErrorLevel, "real message", fields)
+		if entry.Caller == nil || entry.Caller.Function != "main.run" || entry.Caller.File != "/src/app/main.go" || entry.Caller.Line != 42 {
+			t.Errorf("caller = %+v in %s", entry.Caller, line)
+		}
+	}
+}
+
+func TestCaller(t *testing.T) {
+	tests := []struct {
+		file     string
+		wantFile string
+		wantLine int
+	}{
+		{"/src/app/main.go:42", "/src/app/main.go", 42},
+		{`C:\src\main.go:7`, `C:\src\main.go`, 7},
+		{"main.go", "main.go", 0},
+		{"main.go:x", "main.go:x", 0},
+	}
+	for _, tt := range tests {
+		line := fmt.Sprintf(`level=info msg=hi func=main.run file=%q`, tt.file)
+		entry, err := Parse([]byte(line), Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Caller == nil || entry.Caller.Function != "main.run" || entry.Caller.File != tt.wantFile || entry.Caller.Line != tt.wantLine {
+			t.Errorf("%s: caller = %+v", tt.file, entry.Caller)
+		}
+	}
+}
+
+func TestLevelLabels(t *testing.T) {
+	styles := []logy.LevelLabelStyle{
+		logy.LevelLabelDefault,
+		logy.LevelLabelUpper,
+		logy.LevelLabelShort,
+		logy.LevelLabelLetter,
+	}
+	for _, style := range styles {
+		f := &logy.TextFormatter{DisableColors: true, LevelLabelStyle: style}
+		for _, level := range logy.AllLevels {
+			line := format(t, f, level, "hi", nil, nil)
+			entry, err := Parse(line, Options{})
+			if err != nil {
+				t.Errorf("style %d: %v in %s", style, err, line)
+				continue
+			}
+			if entry.Level != level {
+				t.Errorf("style %d: level = %v, want %v in %s", style, entry.Level, level, line)
+			}
+		}
+	}
+
+	// the labels truncated to four letters in colored output
+	for label, want := range map[string]logy.Level{
+		"PANI": logy.PanicLevel,
+		"FATA": logy.FatalLevel,
+		"ERRO": logy.ErrorLevel,
+		"WARN": logy.WarnLevel,
+		"INFO": logy.InfoLevel,
+		"DEBU": logy.DebugLevel,
+		"TRAC": logy.TraceLevel,
+	} {
+		if level, err := ParseLevelLabel(label); err != nil || level != want {
+			t.Errorf("ParseLevelLabel(%q) = %v, %v, want %v", label, level, err, want)
+		}
+	}
+	if _, err := ParseLevelLabel("X"); err == nil {
+		t.Error("ParseLevelLabel(\"X\") succeeded")
+	}
+}
+
+func TestDataKeyKeepsTopLevel(t *testing.T) {
+	line := `{"data":{"level":"user-level","msg":"user-msg","id":1},"level":"error","msg":"real message","logrus_error":"bad field"}`
+	entry, err := Parse([]byte(line), Options{DataKey: "data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != logy.ErrorLevel || entry.Message != "real message" {
+		t.Errorf("got %v %q", entry.Level, entry.Message)
+	}
+	want := logy.Fields{"level": "user-level", "msg": "user-msg", "id": 1, "logrus_error": "bad field"}
+	if len(entry.Data) != len(want) {
+		t.Errorf("fields = %v, want %v", entry.Data, want)
+	}
+	for k, v := range want {
+		if fmt.Sprint(entry.Data[k]) != fmt.Sprint(v) {
+			t.Errorf("field %s = %v, want %v", k, entry.Data[k], v)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	input := "level=info msg=first\n\nnot a log line\n{\"level\":\"warning\",\"msg\":\"third\"}\r\nlevel=error msg=last"
+	r := NewReader(strings.NewReader(input), Options{})
+
+	var msgs []string
+	var syntaxErrors int
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, ErrSyntax) {
+			syntaxErrors++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, entry.Message)
+	}
+	if got := strings.Join(msgs, ","); got != "first,third,last" || syntaxErrors != 1 {
+		t.Errorf("got %s with %d syntax errors", got, syntaxErrors)
+	}
+}
@@ -0,0 +1,678 @@
+package logy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp appended to the name of rotated files.
+const backupTimeFormat = "2006-01-02-15-04-05"
+
+// Common rotation intervals for RotatingFileWriter.RotateInterval.
+const (
+	RotateHourly = time.Hour
+	RotateDaily  = 24 * time.Hour
+)
+
+// RotatingFileWriter is an io.WriteCloser that appends to a log file and
+// rotates it once it would grow past MaxSize or when a RotateInterval
+// boundary is crossed. The file is opened on the first write and kept open
+// until the writer is closed, so it can be handed to Logger.SetOutput like any
+// other writer.
+//
+// Filename may contain strftime-style verbs (%Y, %m, %d, %H, %M, %S, %y, %j
+// and %%), in the file name as well as in its directories, e.g.
+// `logs/%Y/%m/%d/app-%H.log`. They are expanded with the start of the
+// current rotation period, so every period gets its own file.
+//
+// When rotation doesn't change the expanded name, the current file is renamed
+// to `<name>_2006-01-02-15-04-05.log` and a new, empty file is opened under
+// the original name.
+type RotatingFileWriter struct {
+	// Filename is the file to write logs to, possibly a pattern.
+	Filename string
+
+	// MaxSize is the size in bytes the file may reach before it is rotated.
+	// Zero disables size based rotation.
+	MaxSize int64
+
+	// RotateInterval rotates the file every interval, aligned to wall-clock
+	// boundaries in Location: hourly files start on the hour and daily files
+	// at midnight. Zero disables time based rotation. It can be combined with
+	// MaxSize.
+	RotateInterval time.Duration
+
+	// Location is the time zone used to align rotation and to expand the
+	// Filename pattern. Nil means time.Local.
+	Location *time.Location
+
+	// Compress compresses the files this writer rotates out in the
+	// background and removes the uncompressed copy once the compressed one
+	// is on disk.
+	Compress bool
+
+	// Compressor is the codec used when Compress is set. Nil means gzip.
+	Compressor Compressor
+
+	// MaxBackups is the number of rotated files to keep. Zero keeps all.
+	MaxBackups int
+
+	// MaxAge removes rotated files last modified longer ago. Zero keeps all.
+	MaxAge time.Duration
+
+	// MaxTotalSize bounds the size in bytes of the current file and all
+	// rotated ones together; the oldest rotated files are removed first.
+	// Zero means no limit.
+	MaxTotalSize int64
+
+	// OnCleanup is called whenever the retention policy removed files or
+	// failed to, with the files it removed and the first error it met. When
+	// nil errors are printed to stderr.
+	OnCleanup func(removed []string, err error)
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// name is the expanded Filename of the open file
+	name string
+	// next is when the current rotation period ends
+	next time.Time
+
+	// millCh wakes the background goroutine enforcing retention, millDone is
+	// closed once it exited.
+	millCh   chan struct{}
+	millDone chan struct{}
+	// rotated holds the files moved aside by this writer that wait to be
+	// compressed
+	rotated []string
+}
+
+// NewRotatingFileWriter returns a writer appending to filename and rotating
+// it every maxSize bytes.
+func NewRotatingFileWriter(filename string, maxSize int64) *RotatingFileWriter {
+	return &RotatingFileWriter{
+		Filename: filename,
+		MaxSize:  maxSize,
+	}
+}
+
+// Write implements io.Writer. A single write is never split across two files.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if w.file != nil && w.RotateInterval > 0 && !now.Before(w.next) {
+		if w.expand(now) != w.name {
+			// The pattern moves us to a fresh file, nothing to rename.
+			if err := w.close(); err != nil {
+				return 0, err
+			}
+			w.queueCompression(w.name)
+		} else if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.file == nil {
+		if err := w.openExistingOrNew(now); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSize {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it aside and opens a new one.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate(time.Now())
+}
+
+// Reopen closes the current file, which is opened again by name on the next
+// write. Use it after an external tool such as logrotate moved the file.
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.close()
+}
+
+// SetFilename switches the writer to another file. The current file is
+// closed and the new one is opened on the next write.
+func (w *RotatingFileWriter) SetFilename(filename string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.close()
+	w.Filename = filename
+	return err
+}
+
+// SetMaxSize changes the rotation size of the writer.
+func (w *RotatingFileWriter) SetMaxSize(size int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.MaxSize = size
+}
+
+// SetRotateInterval changes the rotation interval and time zone of the
+// writer. The current file is closed so the next write starts a file for
+// the new period layout.
+func (w *RotatingFileWriter) SetRotateInterval(interval time.Duration, loc *time.Location) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.close()
+	w.RotateInterval = interval
+	w.Location = loc
+	return err
+}
+
+// SetRetention changes the retention policy of the writer. It is applied
+// right away in the background.
+func (w *RotatingFileWriter) SetRetention(maxBackups int, maxAge time.Duration, maxTotalSize int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.MaxBackups = maxBackups
+	w.MaxAge = maxAge
+	w.MaxTotalSize = maxTotalSize
+	w.mill()
+}
+
+// SetCompressor changes the codec used for rotated files, nil disables
+// compression.
+func (w *RotatingFileWriter) SetCompressor(compressor Compressor) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.Compress = compressor != nil
+	w.Compressor = compressor
+	w.mill()
+}
+
+// SetOnCleanup changes the function told about the files removed by the
+// retention policy.
+func (w *RotatingFileWriter) SetOnCleanup(fn func(removed []string, err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.OnCleanup = fn
+}
+
+// Sync commits the current file to stable storage.
+func (w *RotatingFileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file and waits for pending background cleanup.
+// A later write opens the file again.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	err := w.close()
+	millCh, millDone := w.millCh, w.millDone
+	w.millCh, w.millDone = nil, nil
+	w.mu.Unlock()
+
+	if millCh != nil {
+		close(millCh)
+		<-millDone
+	}
+	return err
+}
+
+func (w *RotatingFileWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	unmarkActive(w.name)
+	w.file = nil
+	w.size = 0
+	return err
+}
+
+func (w *RotatingFileWriter) openExistingOrNew(now time.Time) error {
+	name := w.expand(now)
+	if dir := filepath.Dir(name); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("can't make directories for new logfile: %w", err)
+		}
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open logfile: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("can't stat logfile: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	w.name = name
+	markActive(name)
+	if w.RotateInterval > 0 {
+		w.next = w.periodEnd(now)
+	}
+	w.mill()
+	return nil
+}
+
+func (w *RotatingFileWriter) rotate(now time.Time) error {
+	name := w.name
+	if name == "" {
+		name = w.expand(now)
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	backup := w.backupName(name, now)
+	if err := os.Rename(name, backup); err == nil {
+		w.queueCompression(backup)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("can't rename logfile: %w", err)
+	}
+	return w.openExistingOrNew(now)
+}
+
+// queueCompression records a file rotated out by the writer for the
+// background goroutine to compress, see mill.
+func (w *RotatingFileWriter) queueCompression(name string) {
+	if w.Compress {
+		w.rotated = append(w.rotated, name)
+	}
+}
+
+// mill asks the background goroutine to compress rotated files and enforce
+// the retention policy, starting it if needed. It never blocks.
+func (w *RotatingFileWriter) mill() {
+	if !w.Compress && w.MaxBackups <= 0 && w.MaxAge <= 0 && w.MaxTotalSize <= 0 {
+		return
+	}
+	if w.millCh == nil {
+		w.millCh = make(chan struct{}, 1)
+		w.millDone = make(chan struct{})
+		go w.millRun(w.millCh, w.millDone)
+	}
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *RotatingFileWriter) millRun(millCh <-chan struct{}, millDone chan<- struct{}) {
+	defer close(millDone)
+	for range millCh {
+		w.mu.Lock()
+		onCleanup := w.OnCleanup
+		rotated := w.rotated
+		w.rotated = nil
+		var compressor Compressor
+		if w.Compress {
+			compressor = w.compressor()
+		}
+		w.mu.Unlock()
+
+		if compressor != nil {
+			compressRotated(rotated, compressor)
+		}
+		removed, err := w.cleanup()
+		if len(removed) == 0 && err == nil {
+			continue
+		}
+		if onCleanup != nil {
+			onCleanup(removed, err)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove old log files, %v\n", err)
+		}
+	}
+}
+
+// logSegment is a log file found on disk by cleanup.
+type logSegment struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// segments returns the rotated files of the writer, newest first, and the
+// size of the current file. Only names the writer could have produced are
+// considered: other expansions of a Filename pattern and the backups made by
+// rotate, compressed or not. The files open in any writer are left out.
+func (w *RotatingFileWriter) segments() ([]logSegment, int64, error) {
+	w.mu.Lock()
+	current := w.name
+	if current == "" {
+		current = w.expand(time.Now())
+	}
+	current = filepath.Clean(current)
+	filename := filepath.Clean(w.Filename)
+	extensions := append([]string{w.compressor().Extension()}, compressedExtensions...)
+	w.mu.Unlock()
+
+	re, err := segmentRegexp(filename, extensions)
+	if err != nil {
+		return nil, 0, err
+	}
+	matches, err := filepath.Glob(globPattern(filename) + "*")
+	if err != nil {
+		return nil, 0, err
+	}
+	var currentSize int64
+	if info, err := os.Stat(current); err == nil {
+		currentSize = info.Size()
+	}
+	segments := make([]logSegment, 0, len(matches))
+	for _, name := range matches {
+		if name == current || !re.MatchString(name) || isActiveFile(name) {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		segments = append(segments, logSegment{name: name, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].modTime.After(segments[j].modTime)
+	})
+	return segments, currentSize, nil
+}
+
+// compressRotated compresses the files the writer rotated out. Files that
+// disappeared in the meantime, e.g. removed by retention, are skipped.
+func compressRotated(names []string, c Compressor) {
+	for _, name := range names {
+		if isCompressed(name, c) {
+			continue
+		}
+		if err := compressFile(name, c); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Failed to compress %s, %v\n", name, err)
+		}
+	}
+}
+
+// cleanup removes the rotated files falling outside the retention policy and
+// returns their names.
+func (w *RotatingFileWriter) cleanup() ([]string, error) {
+	w.mu.Lock()
+	maxBackups, maxAge, maxTotalSize := w.MaxBackups, w.MaxAge, w.MaxTotalSize
+	w.mu.Unlock()
+	if maxBackups <= 0 && maxAge <= 0 && maxTotalSize <= 0 {
+		return nil, nil
+	}
+
+	segments, currentSize, err := w.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var firstErr error
+	total := currentSize
+	for i, seg := range segments {
+		total += seg.size
+		expired := (maxBackups > 0 && i >= maxBackups) ||
+			(maxAge > 0 && time.Since(seg.modTime) > maxAge) ||
+			(maxTotalSize > 0 && total > maxTotalSize)
+		if !expired {
+			continue
+		}
+		if err := os.Remove(seg.name); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		total -= seg.size
+		removed = append(removed, seg.name)
+	}
+	return removed, firstErr
+}
+
+// segmentRegexp returns a regexp matching the files rotated out of pattern:
+// its backups `<name>_2006-01-02-15-04-05[-N].log`, and for patterns with
+// verbs its other expansions as well, optionally followed by one of the
+// compressed extensions.
+func segmentRegexp(pattern string, extensions []string) (*regexp.Regexp, error) {
+	backup := `_\d{4}-\d{2}-\d{2}-\d{2}-\d{2}-\d{2}(-\d+)?\.log`
+	if expandPattern(pattern, time.Time{}) != pattern {
+		backup = "(" + backup + ")?"
+	}
+	quoted := make([]string, len(extensions))
+	for i, ext := range extensions {
+		quoted[i] = regexp.QuoteMeta(ext)
+	}
+	return regexp.Compile("^" + patternRegexp(pattern) + backup + "(" + strings.Join(quoted, "|") + ")?$")
+}
+
+// patternRegexp turns a Filename pattern into a regexp matching its
+// expansions, each verb standing for digits of its fixed width.
+func patternRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' || i+1 == len(pattern) {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			b.WriteString(`\d{4}`)
+		case 'y', 'm', 'd', 'H', 'M', 'S':
+			b.WriteString(`\d{2}`)
+		case 'j':
+			b.WriteString(`\d{3}`)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i-1 : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// activeFiles counts the writers having each file open, so that retention
+// never touches the current file of another writer.
+var (
+	activeMu    sync.Mutex
+	activeFiles = make(map[string]int)
+)
+
+func activeKey(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(name)
+}
+
+func markActive(name string) {
+	activeMu.Lock()
+	activeFiles[activeKey(name)]++
+	activeMu.Unlock()
+}
+
+func unmarkActive(name string) {
+	key := activeKey(name)
+	activeMu.Lock()
+	if activeFiles[key]--; activeFiles[key] <= 0 {
+		delete(activeFiles, key)
+	}
+	activeMu.Unlock()
+}
+
+func isActiveFile(name string) bool {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	return activeFiles[activeKey(name)] > 0
+}
+
+// globPattern turns a Filename pattern into a filepath.Glob pattern matching
+// all of its expansions. Glob metacharacters in the name are escaped where
+// filepath.Match supports it.
+func globPattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '%' && i+1 < len(pattern) && pattern[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case c == '%' && i+1 < len(pattern):
+			b.WriteByte('*')
+			i++
+		case (c == '*' || c == '?' || c == '[') && runtime.GOOS != "windows":
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func (w *RotatingFileWriter) location() *time.Location {
+	if w.Location != nil {
+		return w.Location
+	}
+	return time.Local
+}
+
+// expand returns the file name for the rotation period containing t.
+func (w *RotatingFileWriter) expand(t time.Time) string {
+	if w.RotateInterval > 0 {
+		t = w.periodStart(t)
+	}
+	return expandPattern(w.Filename, t.In(w.location()))
+}
+
+// periodStart returns the start of the rotation period containing t.
+// Intervals of up to a day are counted from local midnight, whole days from
+// the Unix epoch in local calendar days.
+func (w *RotatingFileWriter) periodStart(t time.Time) time.Time {
+	loc := w.location()
+	t = t.In(loc)
+	interval := w.RotateInterval
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch {
+	case interval <= 24*time.Hour:
+		return midnight.Add(t.Sub(midnight) / interval * interval)
+	case interval%(24*time.Hour) == 0:
+		days := int64(interval / (24 * time.Hour))
+		epochDay := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+		return midnight.AddDate(0, 0, -int(epochDay%days))
+	default:
+		return t.Truncate(interval)
+	}
+}
+
+// periodEnd returns the start of the rotation period following the one
+// containing t. Periods never span local midnight unless they last several
+// days.
+func (w *RotatingFileWriter) periodEnd(t time.Time) time.Time {
+	start := w.periodStart(t)
+	interval := w.RotateInterval
+	if interval >= 24*time.Hour && interval%(24*time.Hour) == 0 {
+		return start.AddDate(0, 0, int(interval/(24*time.Hour)))
+	}
+	end := start.Add(interval)
+	if interval <= 24*time.Hour {
+		if midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location()); end.After(midnight) {
+			end = midnight
+		}
+	}
+	return end
+}
+
+// expandPattern replaces the strftime-style verbs in pattern with the fields
+// of t. Unknown verbs are kept as they are.
+func expandPattern(pattern string, t time.Time) string {
+	if !strings.Contains(pattern, "%") {
+		return pattern
+	}
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' || i+1 == len(pattern) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+// backupName returns an unused name to move filename to when it is rotated
+// at t. Names already taken by a compressed backup are skipped as well.
+func (w *RotatingFileWriter) backupName(filename string, t time.Time) string {
+	base := filename + "_" + t.Format(backupTimeFormat)
+	name := base + ".log"
+	for i := 1; w.backupExists(name); i++ {
+		name = fmt.Sprintf("%s-%d.log", base, i)
+	}
+	return name
+}
+
+func (w *RotatingFileWriter) backupExists(name string) bool {
+	if fileExists(name) || fileExists(name+w.compressor().Extension()) {
+		return true
+	}
+	for _, ext := range compressedExtensions {
+		if fileExists(name + ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *RotatingFileWriter) compressor() Compressor {
+	if w.Compressor != nil {
+		return w.Compressor
+	}
+	return &GzipCompressor{}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,291 @@
+package logy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func touch(t *testing.T, name string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRetentionKeepsOtherWritersFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	for i, name := range []string{
+		"20200101.log",
+		"20200102.log",
+		"error-20200101.log",
+		"notes.log",
+		"2020.log",
+	} {
+		touch(t, filepath.Join(dir, name), old.Add(time.Duration(i)*time.Minute))
+	}
+
+	main := NewRotatingFileWriter(filepath.Join(dir, "%Y%m%d.log"), 0)
+	main.RotateInterval = RotateDaily
+	errs := NewRotatingFileWriter(filepath.Join(dir, "error-%Y%m%d.log"), 0)
+	errs.RotateInterval = RotateDaily
+	for _, w := range []*RotatingFileWriter{main, errs} {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	main.SetRetention(1, 0, 0)
+	if err := main.Close(); err != nil {
+		t.Fatal(err)
+	}
+	defer errs.Close()
+
+	today := time.Now().Format("20060102")
+	want := []string{"2020.log", "20200102.log", "error-20200101.log", "error-" + today + ".log", "notes.log", today + ".log"}
+	sort.Strings(want)
+	if got := listDir(t, dir); !equalStrings(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestRetentionPlainName(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	old := time.Now().Add(-time.Hour)
+	touch(t, name+"_2020-01-01-00-00-00.log", old)
+	touch(t, name+"_2020-01-01-00-00-00-1.log.gz", old.Add(time.Minute))
+	touch(t, name+"_2020-01-02-00-00-00.log", old.Add(2*time.Minute))
+	touch(t, name+".bak", old)
+	touch(t, filepath.Join(dir, "app.log_notes.log"), old)
+
+	var removed []string
+	w := NewRotatingFileWriter(name, 0)
+	w.OnCleanup = func(names []string, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		removed = append(removed, names...)
+	}
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.SetRetention(1, 30*time.Minute, 0)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(removed)
+	want := []string{name + "_2020-01-01-00-00-00-1.log.gz", name + "_2020-01-01-00-00-00.log", name + "_2020-01-02-00-00-00.log"}
+	if !equalStrings(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if got := listDir(t, dir); !equalStrings(got, []string{"app.log", "app.log.bak", "app.log_notes.log"}) {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoggerOnCleanup(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{"app.log_2020-01-01-00-00-00.log", "app.log_2020-01-02-00-00-00.log", "error-app.log_2020-01-01-00-00-00.log"} {
+		touch(t, filepath.Join(dir, name), old.Add(time.Duration(i)*time.Minute))
+	}
+
+	var mu sync.Mutex
+	var removed []string
+	l := New()
+	l.SetFilepn("app.log", dir+"/")
+	l.SetOnCleanup(func(names []string, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		removed = append(removed, names...)
+		mu.Unlock()
+	})
+	l.Setifwf(true)
+	l.Error("entry")
+	l.SetRetention(0, 30*time.Minute, 0)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(removed)
+	want := []string{
+		filepath.Join(dir, "app.log_2020-01-01-00-00-00.log"),
+		filepath.Join(dir, "app.log_2020-01-02-00-00-00.log"),
+		filepath.Join(dir, "error-app.log_2020-01-01-00-00-00.log"),
+	}
+	if !equalStrings(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+}
+
+func TestSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app.log"), 100)
+
+	const writers, lines = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				if _, err := w.Write([]byte(fmt.Sprintf("writer %d line %02d\n", i, j))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	files := listDir(t, dir)
+	if len(files) < 2 {
+		t.Fatalf("no rotation happened: %v", files)
+	}
+	for _, name := range files {
+		if name != "app.log" && !regexp.MustCompile(`^app\.log_\d{4}(-\d{2}){5}(-\d+)?\.log$`).MatchString(name) {
+			t.Errorf("unexpected file %s", name)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 100 {
+			t.Errorf("%s has %d bytes, more than MaxSize", name, len(data))
+		}
+		for _, line := range strings.SplitAfter(string(data), "\n") {
+			if line == "" {
+				continue
+			}
+			if !strings.HasSuffix(line, "\n") || seen[line] {
+				t.Errorf("%s: split or repeated line %q", name, line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("%d lines found, want %d", len(seen), writers*lines)
+	}
+}
+
+func TestRotatePeriods(t *testing.T) {
+	w := &RotatingFileWriter{
+		Filename:       "logs/%Y/%m/%d/app-%H.log",
+		RotateInterval: 6 * time.Hour,
+		Location:       time.UTC,
+	}
+	now := time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)
+	if got, want := w.expand(now), "logs/2024/03/09/app-12.log"; got != want {
+		t.Errorf("expand = %s, want %s", got, want)
+	}
+	if got, want := w.periodEnd(now), time.Date(2024, 3, 9, 18, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("periodEnd = %v, want %v", got, want)
+	}
+	if got, want := w.periodEnd(now.Add(6*time.Hour)), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("periodEnd = %v, want %v", got, want)
+	}
+}
+
+func TestTimeRotationPattern(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app-%H%M%S.log"), 0)
+	w.RotateInterval = time.Second
+	defer w.Close()
+
+	first := time.Now()
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Until(first.Truncate(time.Second).Add(time.Second + 50*time.Millisecond)))
+	second := time.Now()
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{first.Format("app-150405.log"), second.Format("app-150405.log")}
+	if got := listDir(t, dir); !equalStrings(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	for i, line := range []string{"first\n", "second\n"} {
+		data, err := os.ReadFile(filepath.Join(dir, want[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != line {
+			t.Errorf("%s = %q, want %q", want[i], data, line)
+		}
+	}
+}
+
+func TestTimeRotationPlainName(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "app.log"), 0)
+	w.RotateInterval = time.Hour
+	defer w.Close()
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	// pretend the period is over
+	w.mu.Lock()
+	w.next = time.Now().Add(-time.Second)
+	w.mu.Unlock()
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	files := listDir(t, dir)
+	if len(files) != 2 || files[0] != "app.log" || !strings.HasPrefix(files[1], "app.log_") {
+		t.Fatalf("files = %v, want a backup and app.log", files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, files[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\n" {
+		t.Errorf("backup = %q, want %q", data, "first\n")
+	}
+}
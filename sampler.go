@@ -0,0 +1,131 @@
+package logy
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SampledKey is the field Sampler.Annotate adds to the entries it lets
+// through past the first ones of a window.
+var SampledKey = "sampled"
+
+// Sampler limits repeated entries: in each Tick window the First entries
+// with a given level and message pass, then only every Thereafter-th one.
+// Fatal and Panic entries are never sampled.
+type Sampler struct {
+	// passed and dropped come first to keep them 64-bit aligned for atomic
+	// access.
+	passed  uint64
+	dropped uint64
+
+	// Tick is the length of a window, one second when zero.
+	Tick time.Duration
+
+	// First is the number of entries passing unconditionally per window.
+	First int
+
+	// Thereafter lets every Thereafter-th entry through once First is
+	// reached. Zero drops them all.
+	Thereafter int
+
+	// Annotate adds a SampledKey field, e.g. sampled=1/100, to the entries
+	// passed after the first ones.
+	Annotate bool
+
+	mu        sync.Mutex
+	windowEnd time.Time
+	counts    map[sampleKey]int
+}
+
+type sampleKey struct {
+	level   Level
+	message string
+}
+
+// NewSampler returns a sampler passing the first entries with the same level
+// and message in every tick, then every thereafter-th one.
+func NewSampler(tick time.Duration, first, thereafter int) *Sampler {
+	return &Sampler{Tick: tick, First: first, Thereafter: thereafter}
+}
+
+// Sample reports whether entry should be logged, counting it.
+func (s *Sampler) Sample(entry *Entry) bool {
+	if entry.Level <= FatalLevel {
+		return true
+	}
+	tick := s.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	if s.counts == nil || !now.Before(s.windowEnd) {
+		// Start over every window, which also bounds the map.
+		s.counts = make(map[sampleKey]int)
+		s.windowEnd = now.Add(tick)
+	}
+	key := sampleKey{entry.Level, entry.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	s.mu.Unlock()
+
+	if n <= s.First {
+		atomic.AddUint64(&s.passed, 1)
+		return true
+	}
+	if s.Thereafter > 0 && (n-s.First)%s.Thereafter == 0 {
+		atomic.AddUint64(&s.passed, 1)
+		if s.Annotate {
+			entry.Data[SampledKey] = fmt.Sprintf("1/%d", s.Thereafter)
+		}
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+// Passed returns the number of entries the sampler let through.
+func (s *Sampler) Passed() uint64 {
+	return atomic.LoadUint64(&s.passed)
+}
+
+// Dropped returns the number of entries the sampler discarded.
+func (s *Sampler) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// SetSampler samples the entries of every level, except those with a
+// sampler of their own. Nil removes it.
+func (logger *Logger) SetSampler(sampler *Sampler) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.sampler = sampler
+}
+
+// SetLevelSampler samples the entries of one level. Nil removes it.
+func (logger *Logger) SetLevelSampler(level Level, sampler *Sampler) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if sampler == nil {
+		delete(logger.levelSamplers, level)
+		return
+	}
+	if logger.levelSamplers == nil {
+		logger.levelSamplers = make(map[Level]*Sampler)
+	}
+	logger.levelSamplers[level] = sampler
+}
+
+// samplerFor returns the sampler applying to level, if any. It is called
+// with the logger lock held.
+func (logger *Logger) samplerFor(level Level) *Sampler {
+	if sampler, ok := logger.levelSamplers[level]; ok {
+		return sampler
+	}
+	return logger.sampler
+}
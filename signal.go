@@ -0,0 +1,101 @@
+package logy
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// signalHandler reacts to the signals registered by HandleSignals.
+type signalHandler struct {
+	ch   chan os.Signal
+	stop chan struct{}
+	done chan struct{}
+}
+
+// HandleSignals starts handling the control signals of the process for the
+// logger: SIGHUP reopens its files, see Reopen, SIGUSR1 raises its level one
+// step towards Trace and SIGUSR2 lowers it one step towards Panic. Level
+// changes are logged at Info whatever the new level. The handler runs until
+// StopSignals or Close is called. Signals are not supported on Windows,
+// where HandleSignals does nothing.
+func (logger *Logger) HandleSignals() {
+	if len(controlSignals) == 0 {
+		return
+	}
+	h := &signalHandler{
+		ch:   make(chan os.Signal, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	logger.mu.Lock()
+	old := logger.signals
+	logger.signals = h
+	logger.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+
+	signal.Notify(h.ch, controlSignals...)
+	go h.run(logger)
+}
+
+// StopSignals stops the handler started by HandleSignals.
+func (logger *Logger) StopSignals() {
+	logger.mu.Lock()
+	h := logger.signals
+	logger.signals = nil
+	logger.mu.Unlock()
+	if h != nil {
+		h.close()
+	}
+}
+
+func (h *signalHandler) run(logger *Logger) {
+	defer close(h.done)
+	for {
+		select {
+		case sig := <-h.ch:
+			logger.handleSignal(sig)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+func (h *signalHandler) close() {
+	signal.Stop(h.ch)
+	close(h.stop)
+	<-h.done
+}
+
+func (logger *Logger) handleSignal(sig os.Signal) {
+	switch sig {
+	case reopenSignal:
+		if err := logger.Reopen(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to reopen log files, %v\n", err)
+			return
+		}
+		logger.WithField("signal", signalNames[sig]).Info("Reopened log files")
+	case raiseSignal:
+		if level := logger.GetLevel(); level < TraceLevel {
+			logger.changeLevel(sig, level+1)
+		}
+	case lowerSignal:
+		if level := logger.GetLevel(); level > PanicLevel {
+			logger.changeLevel(sig, level-1)
+		}
+	}
+}
+
+// changeLevel sets the level and logs the change, bypassing the level check
+// so that it is recorded even when lowering the level hides Info entries.
+func (logger *Logger) changeLevel(sig os.Signal, level Level) {
+	old := logger.GetLevel()
+	logger.SetLevel(level)
+	logger.WithFields(Fields{
+		"signal":         signalNames[sig],
+		"previous_level": old.String(),
+	}).log(InfoLevel, "Log level changed to "+level.String())
+}
@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package logy
+
+import "os"
+
+// The control signals are not available on this platform.
+var (
+	reopenSignal os.Signal
+	raiseSignal  os.Signal
+	lowerSignal  os.Signal
+)
+
+var controlSignals []os.Signal
+
+var signalNames map[os.Signal]string
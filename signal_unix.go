@@ -0,0 +1,22 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package logy
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	reopenSignal os.Signal = syscall.SIGHUP
+	raiseSignal  os.Signal = syscall.SIGUSR1
+	lowerSignal  os.Signal = syscall.SIGUSR2
+)
+
+var controlSignals = []os.Signal{reopenSignal, raiseSignal, lowerSignal}
+
+var signalNames = map[os.Signal]string{
+	reopenSignal: "SIGHUP",
+	raiseSignal:  "SIGUSR1",
+	lowerSignal:  "SIGUSR2",
+}
@@ -0,0 +1,259 @@
+package logy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink is an additional destination for the entries of a Logger, with a
+// formatter and level of its own. Entries are first filtered by the level of
+// the logger, then by Enabled, so a logger needs the most verbose level of
+// its outputs. Sinks sharing a formatter get the same serialized bytes, the
+// entry is formatted only once for all of them, unless colors make the output
+// for terminals differ. Colors are decided from the writer returned by a
+// `Writer() io.Writer` method, as WriterSink has; sinks without one are
+// never taken for terminals.
+type Sink interface {
+	// Formatter returns the formatter of the sink. Nil means the formatter
+	// of the logger.
+	Formatter() Formatter
+
+	// Enabled reports whether the sink takes entries of the given level.
+	Enabled(level Level) bool
+
+	// Open is called by Logger.AddSink before the first write.
+	Open() error
+
+	// Write writes one serialized entry. It is called with the logger lock
+	// held.
+	Write(entry *Entry, p []byte) error
+
+	// Flush writes out anything the sink buffers.
+	Flush() error
+
+	// Close is called by Logger.RemoveSink after a last Flush.
+	Close() error
+}
+
+// WriterSink is a Sink writing to an io.Writer.
+type WriterSink struct {
+	out       io.Writer
+	formatter Formatter
+	level     Level
+	mu        sync.Mutex
+}
+
+// NewWriterSink returns a sink writing the entries up to level to out,
+// serialized with formatter.
+func NewWriterSink(out io.Writer, formatter Formatter, level Level) *WriterSink {
+	return &WriterSink{out: out, formatter: formatter, level: level}
+}
+
+// Writer returns the writer of the sink.
+func (s *WriterSink) Writer() io.Writer {
+	return s.out
+}
+
+func (s *WriterSink) Formatter() Formatter {
+	return s.formatter
+}
+
+// SetLevel changes the level of the sink.
+func (s *WriterSink) SetLevel(level Level) {
+	atomic.StoreUint32((*uint32)(&s.level), uint32(level))
+}
+
+func (s *WriterSink) Enabled(level Level) bool {
+	return Level(atomic.LoadUint32((*uint32)(&s.level))) >= level
+}
+
+func (s *WriterSink) Open() error {
+	return nil
+}
+
+func (s *WriterSink) Write(entry *Entry, p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if lw, ok := s.out.(LevelWriter); ok {
+		_, err = lw.WriteLevel(entry.Level, p)
+	} else {
+		_, err = s.out.Write(p)
+	}
+	return err
+}
+
+// Flush syncs the writer if it supports it.
+func (s *WriterSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch out := s.out.(type) {
+	case interface{ Flush() error }:
+		return out.Flush()
+	case interface{ Sync() error }:
+		if out == os.Stdout || out == os.Stderr {
+			return nil
+		}
+		return out.Sync()
+	}
+	return nil
+}
+
+// Reopen reopens the writer if it supports it, see Logger.Reopen.
+func (s *WriterSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.out.(reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// Close closes the writer if it is an io.Closer, except for the standard
+// streams.
+func (s *WriterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out == os.Stdout || s.out == os.Stderr {
+		return nil
+	}
+	if c, ok := s.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// AddSink opens sink and adds it to the outputs of the logger.
+func (logger *Logger) AddSink(sink Sink) error {
+	logger.own()
+	if err := sink.Open(); err != nil {
+		return err
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.sinks = append(logger.sinks, sink)
+	return nil
+}
+
+// RemoveSink removes sink from the outputs of the logger, then flushes and
+// closes it.
+func (logger *Logger) RemoveSink(sink Sink) error {
+	logger.mu.Lock()
+	found := false
+	for i, s := range logger.sinks {
+		if s == sink {
+			logger.sinks = append(logger.sinks[:i:i], logger.sinks[i+1:]...)
+			found = true
+			break
+		}
+	}
+	logger.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("sink %v is not attached to the logger", sink)
+	}
+	flushErr := sink.Flush()
+	if err := sink.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// Sinks returns the sinks attached to the logger.
+func (logger *Logger) Sinks() []Sink {
+	if out := logger.output(); out != logger {
+		return out.Sinks()
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	return append([]Sink(nil), logger.sinks...)
+}
+
+// serialized is an entry formatted by one formatter, for a terminal or not.
+// sinkWriter is implemented by sinks telling the writer they write to,
+// such as WriterSink.
+type sinkWriter interface {
+	Writer() io.Writer
+}
+
+type serialized struct {
+	formatter Formatter
+	terminal  bool
+	data      []byte
+}
+
+// writeSinks writes entry to every sink enabled for its level. formatted
+// holds the output of the logger formatter, which is reused by sinks sharing
+// it and whose writer is a terminal just as much as Out. It is called with
+// the logger lock held.
+func (entry *Entry) writeSinks(formatted []byte) {
+	if len(entry.Logger.sinks) == 0 {
+		return
+	}
+
+	cache := []serialized{{entry.Logger.Formatter, entry.isTerminal(), formatted}}
+	bufPool := entry.getBufferPool()
+	buffer := entry.Buffer
+	defer func() {
+		entry.Buffer = buffer
+		entry.out = nil
+	}()
+
+	for _, sink := range entry.Logger.sinks {
+		if !sink.Enabled(entry.Level) {
+			continue
+		}
+		formatter := sink.Formatter()
+		if formatter == nil {
+			formatter = entry.Logger.Formatter
+		}
+
+		// colors follow the writer of the sink, not Out
+		entry.out = io.Discard
+		if sw, ok := sink.(sinkWriter); ok && sw.Writer() != nil {
+			entry.out = sw.Writer()
+		}
+		terminal := entry.isTerminal()
+
+		var data []byte
+		for _, s := range cache {
+			if s.terminal == terminal && sameFormatter(s.formatter, formatter) {
+				data = s.data
+				break
+			}
+		}
+		if data == nil {
+			buf := bufPool.Get()
+			buf.Reset()
+			defer bufPool.Put(buf)
+			entry.Buffer = buf
+			var err error
+			data, err = formatter.Format(entry)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
+				continue
+			}
+			cache = append(cache, serialized{formatter, terminal, data})
+		}
+
+		if err := sink.Write(entry, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write to sink, %v\n", err)
+		}
+	}
+}
+
+// sameFormatter reports whether a and b are the same formatter. Formatters
+// of incomparable types are never the same.
+func sameFormatter(a, b Formatter) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,130 @@
+package logy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTerminal returns a file checkIfTerminal takes for a terminal.
+func fakeTerminal(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	terminalFiles.Store(f, true)
+	t.Cleanup(func() {
+		terminalFiles.Delete(f)
+		f.Close()
+	})
+	return f
+}
+
+func plainFile(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readFile(t *testing.T, f *os.File) string {
+	t.Helper()
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSinkColorsFollowWriter(t *testing.T) {
+	setenv(t, "NO_COLOR", "")
+	setenv(t, "FORCE_COLOR", "")
+	for _, formatter := range []Formatter{&TextFormatter{}, &ConsoleFormatter{}} {
+		file := plainFile(t, "app.log")
+		terminal := fakeTerminal(t, "tty")
+
+		l := New()
+		l.SetOutput(file)
+		l.SetFormatter(formatter)
+		if err := l.AddSink(NewWriterSink(terminal, nil, TraceLevel)); err != nil {
+			t.Fatal(err)
+		}
+		l.Warn("to both")
+
+		if got := readFile(t, file); strings.Contains(got, "\x1b[") {
+			t.Errorf("%T: escape codes written to a file: %q", formatter, got)
+		}
+		if got := readFile(t, terminal); !strings.Contains(got, "\x1b[") {
+			t.Errorf("%T: no colors on the terminal sink: %q", formatter, got)
+		}
+	}
+}
+
+func TestSinkNoColorsOnFile(t *testing.T) {
+	setenv(t, "NO_COLOR", "")
+	setenv(t, "FORCE_COLOR", "")
+	terminal := fakeTerminal(t, "tty")
+	file := plainFile(t, "sink.log")
+
+	l := New()
+	l.SetOutput(terminal)
+	if err := l.AddSink(NewWriterSink(file, &TextFormatter{}, TraceLevel)); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("to both")
+
+	if got := readFile(t, terminal); !strings.Contains(got, "\x1b[") {
+		t.Errorf("no colors on the terminal: %q", got)
+	}
+	if got := readFile(t, file); strings.Contains(got, "\x1b[") {
+		t.Errorf("escape codes written to the file sink: %q", got)
+	}
+}
+
+// fileSink is a custom sink writing to a file, sharing the logger formatter.
+type fileSink struct {
+	file *os.File
+}
+
+func (s *fileSink) Formatter() Formatter     { return nil }
+func (s *fileSink) Enabled(level Level) bool { return true }
+func (s *fileSink) Open() error              { return nil }
+func (s *fileSink) Flush() error             { return nil }
+func (s *fileSink) Close() error             { return nil }
+func (s *fileSink) Write(_ *Entry, p []byte) error {
+	_, err := s.file.Write(p)
+	return err
+}
+
+// terminalSink is a fileSink telling its writer.
+type terminalSink struct{ fileSink }
+
+func (s *terminalSink) Writer() io.Writer { return s.file }
+
+func TestCustomSinkColors(t *testing.T) {
+	setenv(t, "NO_COLOR", "")
+	setenv(t, "FORCE_COLOR", "")
+	l := New()
+	l.SetOutput(fakeTerminal(t, "tty"))
+	plain := &fileSink{file: plainFile(t, "sink.log")}
+	terminal := &terminalSink{fileSink{file: fakeTerminal(t, "tty2")}}
+	for _, sink := range []Sink{plain, terminal} {
+		if err := l.AddSink(sink); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Error("to all")
+
+	if got := readFile(t, plain.file); strings.Contains(got, "\x1b[") {
+		t.Errorf("escape codes written to a sink without a writer: %q", got)
+	}
+	if got := readFile(t, terminal.file); !strings.Contains(got, "\x1b[") {
+		t.Errorf("no colors on a sink writing to a terminal: %q", got)
+	}
+}
@@ -3,9 +3,17 @@ package logy
 import (
 	"fmt"
 	"os"
+	"sync"
+	"time"
 )
 
-var handlers = []func(){}
+// defaultExitTimeout bounds the time exit handlers may take in total.
+const defaultExitTimeout = 5 * time.Second
+
+var (
+	handlers   = []func(){}
+	handlersMu sync.Mutex
+)
 
 func runHandler(handler func()) {
 	defer func() {
@@ -17,20 +25,76 @@ func runHandler(handler func()) {
 	handler()
 }
 
-func runHandlers() {
-	for _, handler := range handlers {
-		runHandler(handler)
+// runHandlers runs handlers one after another, recovering from their panics.
+// It gives up waiting once timeout has passed, unless timeout is zero.
+func runHandlers(handlers []func(), timeout time.Duration) {
+	if len(handlers) == 0 {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, handler := range handlers {
+			runHandler(handler)
+		}
+	}()
+
+	if timeout <= 0 {
+		<-done
+		return
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		fmt.Fprintf(os.Stderr, "Error: Logrus exit handlers timed out after %v\n", timeout)
 	}
 }
 
-// Exit runs all the Logrus atexit handlers and then terminates the program using os.Exit(code)
+// globalHandlers returns a copy of the handlers registered on the package.
+func globalHandlers() []func() {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	return append([]func(){}, handlers...)
+}
+
+// Exit runs the exit handlers of the standard logger and of the package, then
+// terminates the program using the ExitFunc of the standard logger, os.Exit
+// by default.
 func Exit(code int) {
-	runHandlers()
-	os.Exit(code)
+	std.Exit(code)
 }
+
+// RegisterExitHandler appends a handler to the list run on Exit by every
+// logger, after their own handlers.
 func RegisterExitHandler(handler func()) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
 	handlers = append(handlers, handler)
 }
+
+// DeferExitHandler prepends a handler to the list run on Exit by every
+// logger, after their own handlers.
 func DeferExitHandler(handler func()) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
 	handlers = append([]func(){handler}, handlers...)
 }
+
+// RegisterExitHandler appends a handler to the list run when the logger
+// exits, e.g. from Fatal.
+func (logger *Logger) RegisterExitHandler(handler func()) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.exitHandlers = append(logger.exitHandlers, handler)
+}
+
+// DeferExitHandler prepends a handler to the list run when the logger exits.
+func (logger *Logger) DeferExitHandler(handler func()) {
+	logger.own()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.exitHandlers = append([]func(){handler}, logger.exitHandlers...)
+}
@@ -0,0 +1,49 @@
+package logy
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// terminalFiles caches the result of checkIfTerminal for files, which are
+// checked for every colored entry.
+var terminalFiles sync.Map
+
+// checkIfTerminal reports whether w is a terminal.
+func checkIfTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+	if isTerminal, ok := terminalFiles.Load(f); ok {
+		return isTerminal.(bool)
+	}
+	isTerminal := isTerminalFd(int(f.Fd()))
+	terminalFiles.Store(f, isTerminal)
+	return isTerminal
+}
+
+// colorChoice is what the options of a formatter and the environment decide
+// about colors, before looking at the output.
+type colorChoice int
+
+const (
+	colorAuto colorChoice = iota
+	colorNever
+	colorAlways
+)
+
+// envColors reads the NO_COLOR and FORCE_COLOR environment variables, see
+// https://no-color.org and https://force-color.org. NO_COLOR wins when both
+// are set; FORCE_COLOR counts when not empty, "0" or "false".
+func envColors() colorChoice {
+	if os.Getenv("NO_COLOR") != "" {
+		return colorNever
+	}
+	switch os.Getenv("FORCE_COLOR") {
+	case "", "0", "false":
+		return colorAuto
+	}
+	return colorAlways
+}
@@ -0,0 +1,17 @@
+//go:build linux
+// +build linux
+
+package logy
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd reports whether fd refers to a terminal, i.e. answers the
+// TCGETS ioctl.
+func isTerminalFd(fd int) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package logy
+
+// isTerminalFd is only implemented on Linux, elsewhere colors have to be
+// forced.
+func isTerminalFd(fd int) bool {
+	return false
+}
@@ -1,32 +1,39 @@
 package logy
-/*
+
 import (
-	"bufio"
+	"bytes"
 	"io"
-	"runtime"
+	"sync"
 )
 
+// maxLineSize is the longest line a LineWriter logs as a single entry. Longer
+// lines are split into several entries of at most this many bytes. It matches
+// the default bufio.Scanner token limit.
+const maxLineSize = 64 * 1024
+
 // Writer at INFO level. See WriterLevel for details.
-func (logger *Logger) Writer() *io.PipeWriter {
+func (logger *Logger) Writer() *LineWriter {
 	return logger.WriterLevel(InfoLevel)
 }
 
 // WriterLevel returns an io.Writer that can be used to write arbitrary text to
 // the logger at the given log level. Each line written to the writer will be
-// printed in the usual way using formatters and hooks. The writer is part of an
-// io.Pipe and it is the callers responsibility to close the writer when done.
-// This can be used to override the standard library logger easily.
-func (logger *Logger) WriterLevel(level Level) *io.PipeWriter {
+// printed in the usual way using formatters and hooks. It is the callers
+// responsibility to close the writer when done, so that a trailing partial
+// line is logged. This can be used to override the standard library logger
+// easily, e.g. for http.Server.ErrorLog.
+func (logger *Logger) WriterLevel(level Level) *LineWriter {
 	return NewEntry(logger).WriterLevel(level)
 }
 
-func (entry *Entry) Writer() *io.PipeWriter {
+// Writer at INFO level. See WriterLevel for details.
+func (entry *Entry) Writer() *LineWriter {
 	return entry.WriterLevel(InfoLevel)
 }
 
-func (entry *Entry) WriterLevel(level Level) *io.PipeWriter {
-	reader, writer := io.Pipe()
-
+// WriterLevel returns a LineWriter logging every line at the given level with
+// the fields of the entry.
+func (entry *Entry) WriterLevel(level Level) *LineWriter {
 	var printFunc func(args ...interface{})
 
 	switch level {
@@ -48,24 +55,89 @@ func (entry *Entry) WriterLevel(level Level) *io.PipeWriter {
 		printFunc = entry.Print
 	}
 
-	go entry.writerScanner(reader, printFunc)
-	runtime.SetFinalizer(writer, writerFinalizer)
+	return &LineWriter{printFunc: printFunc}
+}
 
-	return writer
+// LineWriter is an io.WriteCloser that logs each line written to it as one
+// entry. Writes are synchronous: complete lines are logged before Write
+// returns, while a trailing partial line is kept until the rest of it arrives
+// or the writer is flushed or closed.
+type LineWriter struct {
+	mu        sync.Mutex
+	printFunc func(args ...interface{})
+	buf       []byte
+	closed    bool
 }
 
-func (entry *Entry) writerScanner(reader *io.PipeReader, printFunc func(args ...interface{})) {
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		printFunc(scanner.Text())
+// Write logs every complete line in p. It never returns a short count unless
+// the writer is closed.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
 	}
-	if err := scanner.Err(); err != nil {
-		entry.Errorf("Error while reading from Writer: %s", err)
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			break
+		}
+		line := p[:i]
+		if len(w.buf) > 0 {
+			w.buf = append(w.buf, line...)
+			line = w.buf
+		}
+		w.printLine(line)
+		w.buf = w.buf[:0]
+		p = p[i+1:]
+	}
+
+	// Don't let an unterminated line grow without bound.
+	if len(w.buf) >= maxLineSize {
+		end := len(w.buf) - len(w.buf)%maxLineSize
+		w.printLine(w.buf[:end])
+		w.buf = append(w.buf[:0], w.buf[end:]...)
 	}
-	reader.Close()
+	return n, nil
 }
 
-func writerFinalizer(writer *io.PipeWriter) {
-	writer.Close()
+// Flush logs the pending partial line, if any.
+func (w *LineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+	return nil
+}
+
+// Close flushes the pending partial line. Writes after Close fail with
+// io.ErrClosedPipe.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.flush()
+	w.closed = true
+	return nil
+}
+
+func (w *LineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.printLine(w.buf)
+		w.buf = w.buf[:0]
+	}
+}
+
+// printLine logs line, split into chunks of maxLineSize bytes.
+func (w *LineWriter) printLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	for len(line) > maxLineSize {
+		w.printFunc(string(line[:maxLineSize]))
+		line = line[maxLineSize:]
+	}
+	w.printFunc(string(line))
 }
-*/
\ No newline at end of file